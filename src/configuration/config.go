@@ -9,11 +9,144 @@ import (
 	"golang.org/x/xerrors"
 )
 
+// LayoutConfig describes where values are in a monthly sheet.
+// Columns are given by letters such as "C", and empty fields take the default.
+type LayoutConfig struct {
+	// HeaderRow is the row number just above the first day (default: 3)
+	HeaderRow int
+	// DateColumn is the column of dates formatted as M/D (default: "A")
+	DateColumn string
+	// PeriodCount is the number of pairs of start and end columns from column C,
+	// which shifts the default of the following columns (default: 3)
+	PeriodCount int
+	// PeriodColumns are the pairs of start and end columns, which take
+	// precedence over PeriodCount (default: [["C", "D"], ["E", "F"], ["G", "H"]])
+	PeriodColumns [][2]string
+	// DurationColumn is the column of daily durations, whose row next to
+	// the last day holds the monthly total (default: "I", or the column after
+	// the periods from column C if there are not 3 pairs)
+	DurationColumn string
+	// TravelNoteColumn and TravelExpenseColumn are the columns of travel
+	// expenses (default: "J" and "K" following the duration column, "-" for none)
+	TravelNoteColumn    string
+	TravelExpenseColumn string
+}
+
+// RoundingConfig is how times of start and end are rounded.
+type RoundingConfig struct {
+	// Unit is the unit of rounding in minutes (default: 10)
+	Unit int
+	// Start and End are the directions of rounding: "up" (default), "down", "nearest" or "none"
+	Start string
+	End   string
+}
+
+// BreakConfig is the break required in a day by the length of work.
+type BreakConfig struct {
+	// Rules are the breaks required over lengths of work
+	// (default: 45 minutes over 6 hours and 60 minutes over 8 hours)
+	Rules []BreakRuleConfig
+	// Action is what to do if gaps between periods are shorter than required:
+	// "warn" (default) or "deduct" from the duration
+	Action string
+}
+
+type BreakRuleConfig struct {
+	OverHours    int
+	BreakMinutes int
+}
+
+// PremiumConfig is the thresholds of work paid at premium rates.
+type PremiumConfig struct {
+	// DailyHours and WeeklyHours are the regular hours beyond which work is overtime (default: 8 and 40)
+	DailyHours  int
+	WeeklyHours int
+	// NightStart and NightEnd are the late-night hours as HH:MM (default: "22:00" and "05:00")
+	NightStart string
+	NightEnd   string
+	// HolidayWeekdays are the days of the week off such as "Sun" (default: ["Sun"]),
+	// in addition to the holidays of the calendar
+	HolidayWeekdays []string
+}
+
+// CalendarConfig is the working days of a project.
+type CalendarConfig struct {
+	// DaysOff are the days of the week off (default: ["Sat", "Sun"])
+	DaysOff []string
+	// NationalHolidays enables Japanese national holidays (default: true)
+	NationalHolidays *bool
+	// Holidays are company holidays of the project by YYYY-MM-DD, added to the global ones
+	Holidays map[string]string
+	// DailyHours is the expected hours of a working day (default: 8)
+	DailyHours int
+}
+
+// BillingConfig is how work of a project is invoiced.
+type BillingConfig struct {
+	// HourlyRate is the price of an hour of work in the currency
+	HourlyRate float64
+	// Currency is the ISO 4217 code of amounts (default: "JPY"), where
+	// travel expenses are in the same currency
+	Currency string
+	// TaxRate is the consumption tax rate in percent (default: 10)
+	TaxRate *float64
+	// Unit is the unit of billed time in minutes (default: 1)
+	Unit int
+	// Rounding is the direction of rounding of billed time, amounts and
+	// tax: "down" (default), "up" or "nearest"
+	Rounding string
+	// InvoiceNumber is the format of invoice numbers, where {yyyy}, {mm},
+	// {project} and {seq} are replaced (default: "{yyyy}{mm}-{seq}")
+	InvoiceNumber string
+	// DueDays is the number of days from issue to the due date (default: 30)
+	DueDays int
+}
+
+type SpreadsheetConfig struct {
+	Id   string
+	Name string
+	// Storage overrides the global storage for the project
+	Storage string
+	// Layout is the layout of sheets in the spreadsheet storage
+	Layout *LayoutConfig
+	// Rounding is the rounding of times of start and end
+	Rounding *RoundingConfig
+	// Break enables the check of breaks between periods
+	Break *BreakConfig
+	// Premium is the thresholds of overtime, late-night and holiday work
+	Premium *PremiumConfig
+	// Calendar is the working days and holidays
+	Calendar *CalendarConfig
+	// TimeZone overrides the global time zone for the project
+	TimeZone string
+	// Client is the name of the client shown in reports
+	Client string
+	// Billing enables invoices of the project
+	Billing *BillingConfig
+}
+
 type ConfigFile struct {
-	Spreadsheets []*struct {
-		Id   string
-		Name string
-	}
+	// Storage is the backend of work time records: "spreadsheet" (default), "file", "sqlite" or "memory"
+	Storage string
+	// DataDir is the directory of the file storage (default: "data" in the config directory)
+	DataDir string
+	// Database is the path of the sqlite storage (default: "worktime.db" in DataDir)
+	Database string
+	// TimeZone is the IANA time zone name of times in records (default: "Asia/Tokyo")
+	TimeZone string
+	// Holidays are company holidays of all projects by YYYY-MM-DD with their names
+	Holidays map[string]string
+	// Worker is the name of the person who works, shown in reports
+	Worker string
+	// RegistrationNumber is the number of the registered invoice issuer such
+	// as "T1234567890123", shown in invoices
+	RegistrationNumber string
+	// Font is the path of a TrueType font for reports, which is required to
+	// render Japanese (default: Helvetica, which renders only Latin-1)
+	Font string
+	// MaxPeriodHours is the longest period which is regarded as valid (default: 16)
+	MaxPeriodHours int
+	Spreadsheets   []*SpreadsheetConfig
 }
 
 type Config struct {
@@ -39,11 +172,81 @@ func Load(settingsDir string) *Config {
 	return &config
 }
 
-func (this *Config) FindSpreadsheetId(name string) (string, error) {
+func (this *Config) FindSpreadsheet(name string) (*SpreadsheetConfig, error) {
 	for _, sheet := range this.Spreadsheets {
 		if sheet.Name == name {
-			return sheet.Id, nil
+			return sheet, nil
 		}
 	}
-	return "", xerrors.Errorf("Spreadsheet not found: %s", name)
+	return nil, xerrors.Errorf("Spreadsheet not found: %s", name)
+}
+
+func (this *Config) FindSpreadsheetId(name string) (string, error) {
+	sheet, err := this.FindSpreadsheet(name)
+	if err != nil {
+		return "", err
+	}
+	return sheet.Id, nil
+}
+
+func (this *Config) GetStorage(name string) (string, error) {
+	sheet, err := this.FindSpreadsheet(name)
+	if err != nil {
+		return "", err
+	}
+	if sheet.Storage != "" {
+		return sheet.Storage, nil
+	}
+	if this.Storage != "" {
+		return this.Storage, nil
+	}
+	return "spreadsheet", nil
+}
+
+func (this *Config) GetDataDir() string {
+	if this.DataDir == "" {
+		return filepath.Join(this.Dir, "data")
+	}
+	if filepath.IsAbs(this.DataDir) {
+		return this.DataDir
+	}
+	return filepath.Join(this.Dir, this.DataDir)
+}
+
+func (this *Config) GetDatabasePath() string {
+	if this.Database == "" {
+		return filepath.Join(this.GetDataDir(), "worktime.db")
+	}
+	if filepath.IsAbs(this.Database) {
+		return this.Database
+	}
+	return filepath.Join(this.Dir, this.Database)
+}
+
+func (this *Config) GetTimeZone(name string) (string, error) {
+	sheet, err := this.FindSpreadsheet(name)
+	if err != nil {
+		return "", err
+	}
+	if sheet.TimeZone != "" {
+		return sheet.TimeZone, nil
+	}
+	if this.TimeZone != "" {
+		return this.TimeZone, nil
+	}
+	return "Asia/Tokyo", nil
+}
+
+func (this *Config) GetMaxPeriodHours() int {
+	if this.MaxPeriodHours <= 0 {
+		return 16
+	}
+	return this.MaxPeriodHours
+}
+
+func (this *Config) GetFontPath() string {
+	if this.Font == "" || filepath.IsAbs(this.Font) {
+		return this.Font
+	}
+	return filepath.Join(this.Dir, this.Font)
 }
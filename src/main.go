@@ -1,57 +1,399 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v2"
+
 	"work-time-logging/configuration"
+	"work-time-logging/report"
 	"work-time-logging/spreadsheet"
 	"work-time-logging/worktime"
 )
 
 type showCmdArgs struct {
 	projectName string
+	breakdown   bool
+}
+
+// Results of commands for the json and yaml output.
+
+type showResult struct {
+	Project               string                    `json:"project" yaml:"project"`
+	Year                  int                       `json:"year" yaml:"year"`
+	Month                 int                       `json:"month" yaml:"month"`
+	Records               []worktime.ExportedRecord `json:"records" yaml:"records"`
+	Holidays              map[string]string         `json:"holidays" yaml:"holidays"`
+	TotalMinutes          int                       `json:"totalMinutes" yaml:"totalMinutes"`
+	RoundingMinutes       int                       `json:"roundingMinutes" yaml:"roundingMinutes"`
+	BreakDeductionMinutes int                       `json:"breakDeductionMinutes" yaml:"breakDeductionMinutes"`
+	ExpectedMinutes       int                       `json:"expectedMinutes" yaml:"expectedMinutes"`
+	WorkingDays           int                       `json:"workingDays" yaml:"workingDays"`
+	Pending               int                       `json:"pending" yaml:"pending"`
+}
+
+type breakdownMinutes struct {
+	Date      string `json:"date,omitempty" yaml:"date,omitempty"`
+	Regular   int    `json:"regular" yaml:"regular"`
+	Overtime  int    `json:"overtime" yaml:"overtime"`
+	LateNight int    `json:"lateNight" yaml:"lateNight"`
+	Holiday   int    `json:"holiday" yaml:"holiday"`
+}
+
+type breakdownResult struct {
+	Project string             `json:"project" yaml:"project"`
+	Year    int                `json:"year" yaml:"year"`
+	Month   int                `json:"month" yaml:"month"`
+	Days    []breakdownMinutes `json:"days" yaml:"days"`
+	Total   breakdownMinutes   `json:"total" yaml:"total"`
+}
+
+type switchResult struct {
+	Ended   *worktime.Change `json:"ended,omitempty" yaml:"ended,omitempty"`
+	Started *worktime.Change `json:"started" yaml:"started"`
+}
+
+type statusResult struct {
+	Project        string `json:"project" yaml:"project"`
+	Start          string `json:"start" yaml:"start"`
+	ElapsedMinutes int    `json:"elapsedMinutes" yaml:"elapsedMinutes"`
+}
+
+type syncResult struct {
+	Synced  []string `json:"synced" yaml:"synced"`
+	Skipped string   `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+}
+
+type checkIssue struct {
+	Kind    string `json:"kind" yaml:"kind"`
+	Project string `json:"project" yaml:"project"`
+	Date    string `json:"date,omitempty" yaml:"date,omitempty"`
+	Slot    int    `json:"slot,omitempty" yaml:"slot,omitempty"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type checkResult struct {
+	Year   int          `json:"year" yaml:"year"`
+	Month  int          `json:"month" yaml:"month"`
+	Issues []checkIssue `json:"issues" yaml:"issues"`
+}
+
+type importChange struct {
+	Action  string `json:"action" yaml:"action"`
+	Project string `json:"project" yaml:"project"`
+	Date    string `json:"date" yaml:"date"`
+	// Slot is 1-based, given to periods to be added
+	Slot    int    `json:"slot,omitempty" yaml:"slot,omitempty"`
+	Start   string `json:"start,omitempty" yaml:"start,omitempty"`
+	End     string `json:"end,omitempty" yaml:"end,omitempty"`
+	Expense int    `json:"expense,omitempty" yaml:"expense,omitempty"`
+	Note    string `json:"note,omitempty" yaml:"note,omitempty"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+type importResult struct {
+	Changes   []importChange `json:"changes" yaml:"changes"`
+	Added     int            `json:"added" yaml:"added"`
+	Skipped   int            `json:"skipped" yaml:"skipped"`
+	Conflicts int            `json:"conflicts" yaml:"conflicts"`
+	// Written is the number of entries written, which is 0 for dry run
+	Written int  `json:"written" yaml:"written"`
+	DryRun  bool `json:"dryRun" yaml:"dryRun"`
+}
+
+type linkResult struct {
+	Project string `json:"project" yaml:"project"`
+	Link    string `json:"link" yaml:"link"`
 }
 
 type startCmdArgs struct {
 	projectName string
+	date        string
 	time        string
 }
 
 type endCmdArgs struct {
 	projectName string
+	date        string
 	time        string
 }
 
 type travelCmdArgs struct {
 	projectName string
+	date        string
 	expense     int
 	note        string
 }
 
+type editCmdArgs struct {
+	projectName string
+	date        string
+	slot        int
+	start       string
+	end         string
+	clear       bool
+}
+
+type checkCmdArgs struct {
+	month  string
+	strict bool
+}
+
+type reportCmdArgs struct {
+	projectName string
+	month       string
+	format      string
+	output      string
+}
+
+type invoiceCmdArgs struct {
+	projectName string
+	month       string
+	format      string
+	output      string
+}
+
+type exportCmdArgs struct {
+	projectNames []string
+	month        string
+	from         string
+	to           string
+	format       string
+	output       string
+}
+
+type importCmdArgs struct {
+	path          string
+	format        string
+	mapping       string
+	projectName   string
+	dryRun        bool
+	skipConflicts bool
+	batchSize     int
+}
+
 type linkCmdArgs struct {
 	projectName string
 }
 
+type switchCmdArgs struct {
+	projectName string
+	time        string
+}
+
+type statusCmdArgs struct {
+}
+
+type syncCmdArgs struct {
+	skip bool
+}
+
+func newStorage(config *configuration.Config) worktime.Storage {
+	return worktime.NewProjectStorage(config, func(kind string) (worktime.Storage, error) {
+		switch kind {
+		case "spreadsheet":
+			if !spreadsheet.HasCredentials(config.Dir) {
+				return nil, fmt.Errorf("Client secret file of Google Sheets not found in %s", config.Dir)
+			}
+			return worktime.NewSpreadsheetStorage(spreadsheet.New(config), config), nil
+		case "file":
+			return worktime.NewFileStorage(config.GetDataDir()), nil
+		case "sqlite":
+			return worktime.NewSQLiteStorage(config.GetDatabasePath())
+		case "memory":
+			return worktime.NewMemoryStorage(), nil
+		default:
+			return nil, fmt.Errorf("Invalid storage: %s", kind)
+		}
+	})
+}
+
+func newJournal(config *configuration.Config) *worktime.Journal {
+	return worktime.NewJournal(filepath.Join(config.Dir, "journal.jsonl"))
+}
+
+// applyOrQueue writes entry, or queues it in the journal when the storage is
+// unreachable or earlier entries are waiting for sync, and reports whether it is queued.
+func applyOrQueue(w *worktime.WorkTime, entry *worktime.JournalEntry, config *configuration.Config) bool {
+	journal := newJournal(config)
+	pending, err := journal.Load()
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	if len(pending) == 0 {
+		switch entry.Operation {
+		case "start":
+			err = w.SetStart(entry.ProjectName, &entry.Date, entry.Time)
+		case "end":
+			err = w.SetEnd(entry.ProjectName, &entry.Date, entry.Time)
+		case "travel":
+			err = w.SetTravelExpense(entry.ProjectName, &entry.Date, entry.Expense, entry.Note)
+		}
+		if err == nil {
+			return false
+		}
+		var unavailableErr *worktime.UnavailableError
+		if !xerrors.As(err, &unavailableErr) {
+			log.Fatal(err)
+		}
+		log.Println(err)
+	}
+
+	entry.CreatedAt = time.Now()
+	if err := journal.Append(entry); err != nil {
+		log.Fatalf("%+v", err)
+	}
+	log.Printf("Queued (%d pending), run sync later: %s", len(pending)+1, entry)
+	return true
+}
+
+// outputFormat is the format of results given by the global -output option:
+// "table" for people, or "json" or "yaml" for scripts.
+var outputFormat string
+
+func printResult(v interface{}) {
+	if outputFormat == "yaml" {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(b)
+		return
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func getChange(w *worktime.WorkTime, operation, projectName string, date *worktime.Date, slot int, columns []string) *worktime.Change {
+	change, err := w.GetChange(operation, projectName, date, slot, columns)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	return change
+}
+
+// printEntry prints the change of entry written by applyOrQueue unless the
+// output is a table, where nothing is printed on success.
+func printEntry(w *worktime.WorkTime, entry *worktime.JournalEntry, queued bool) {
+	if outputFormat == "table" {
+		return
+	}
+	if queued {
+		printResult(&worktime.Change{Operation: entry.Operation, Project: entry.ProjectName, Date: entry.Date.String(), Queued: true})
+		return
+	}
+	if entry.Operation == "travel" {
+		printResult(getChange(w, entry.Operation, entry.ProjectName, &entry.Date, -1, []string{"travel"}))
+		return
+	}
+	date, slot, err := w.FindSlot(entry.ProjectName, &entry.Date, entry.Operation, entry.Time)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	printResult(getChange(w, entry.Operation, entry.ProjectName, date, slot, []string{entry.Operation}))
+}
+
+// projectNow returns the current date and time in the time zone of the project.
+func projectNow(projectName string, config *configuration.Config) (*worktime.Date, *worktime.Time) {
+	location, err := worktime.GetLocation(config, projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return worktime.Now(location)
+}
+
+// resolveDate returns the date given by -date, or today if it is empty.
+func resolveDate(value string, today *worktime.Date) *worktime.Date {
+	if value == "" {
+		return today
+	}
+	date, err := worktime.ParseDate(value, today)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return date
+}
+
+// resolveMonth parses YYYY-MM, or returns the default if value is empty.
+func resolveMonth(value string, year, month int) (int, int) {
+	if value == "" {
+		return year, month
+	}
+	m, err := time.Parse("2006-01", value)
+	if err != nil {
+		log.Fatalf("Invalid month: %s", value)
+	}
+	return m.Year(), int(m.Month())
+}
+
+func newRoundingLog(config *configuration.Config) *worktime.RoundingLog {
+	return worktime.NewRoundingLog(filepath.Join(config.Dir, "rounding.jsonl"))
+}
+
+// roundTime rounds t by the rounding policy of the project, and records
+// the difference so that show can tell it.
+func roundTime(t *worktime.Time, startOrEnd, projectName string, date *worktime.Date, config *configuration.Config) *worktime.Time {
+	policy, err := worktime.GetRoundingPolicy(config, projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var rounded *worktime.Time
+	if startOrEnd == "start" {
+		rounded = policy.RoundStart(t)
+	} else {
+		rounded = policy.RoundEnd(t)
+	}
+
+	if *rounded != *t {
+		err := newRoundingLog(config).Append(&worktime.RoundingLogEntry{
+			Operation:   startOrEnd,
+			ProjectName: projectName,
+			Date:        *date,
+			Raw:         *t,
+			Rounded:     *rounded,
+		})
+		if err != nil {
+			log.Printf("%v", err)
+		}
+	}
+
+	return rounded
+}
+
 func doShow(args *showCmdArgs, config *configuration.Config) {
-	s := spreadsheet.New(config)
-	w := worktime.New(s, config)
+	w := worktime.New(newStorage(config), config)
 
-	now := time.Now()
+	today, _ := projectNow(args.projectName, config)
 
-	monthlyWorkTime, err := w.Get(args.projectName, now.Year(), int(now.Month()))
+	monthlyWorkTime, err := w.Get(args.projectName, today.Year, today.Month)
 	if err != nil {
 		log.Fatalf("%+v", err)
 	}
+	for _, d := range monthlyWorkTime.Diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", d.String())
+	}
+	if args.breakdown {
+		showBreakdown(w, args.projectName, today, config)
+		return
+	}
 
-	formatWeekday := func(d *worktime.Date) string {
-		w := d.GetWeekday()
-		return []string{"日", "月", "火", "水", "木", "金", "土"}[w]
+	calendar, err := worktime.GetCalendar(config, args.projectName)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	formatPeriod := func(p worktime.Period) string {
@@ -86,99 +428,775 @@ func doShow(args *showCmdArgs, config *configuration.Config) {
 		return fmt.Sprintf("%d:%02d", h, m)
 	}
 
+	periodCount := 0
 	for _, record := range monthlyWorkTime.Records {
-		fmt.Printf("%2d/%2d (%s)  %11s  %11s  %11s    %5s | %s\n",
-			record.Date.Month,
-			record.Date.Day,
-			formatWeekday(record.Date),
-			formatPeriod(record.Periods[0]),
-			formatPeriod(record.Periods[1]),
-			formatPeriod(record.Periods[2]),
-			formatDuration(record.GetDuration(), true),
-			formatTravelExpense(record.TravelExpense))
-	}
-
-	fmt.Println("-------------------------------------------------------------")
-	fmt.Printf("Total:                                         %6s\n",
+		if len(record.Periods) > periodCount {
+			periodCount = len(record.Periods)
+		}
+	}
+
+	adjustments, err := newRoundingLog(config).GetAdjustments(args.projectName, monthlyWorkTime)
+	if err != nil {
+		log.Printf("%v", err)
+	}
+	var totalAdjustment time.Duration
+	for _, d := range adjustments {
+		totalAdjustment += d
+	}
+
+	formatAdjustment := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		sign := "+"
+		if d < 0 {
+			sign = "-"
+			d = -d
+		}
+		return sign + formatDuration(d, false)
+	}
+
+	breakPolicy, err := worktime.GetBreakPolicy(config, args.projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	showBreaks := len(breakPolicy.Rules) > 0
+
+	if outputFormat != "table" {
+		result := &showResult{
+			Project:               args.projectName,
+			Year:                  today.Year,
+			Month:                 today.Month,
+			Records:               []worktime.ExportedRecord{},
+			Holidays:              map[string]string{},
+			TotalMinutes:          worktime.ToMinutes(monthlyWorkTime.GetDuration()),
+			RoundingMinutes:       worktime.ToMinutes(totalAdjustment),
+			BreakDeductionMinutes: worktime.ToMinutes(monthlyWorkTime.GetBreakDeduction()),
+		}
+		for i := range monthlyWorkTime.Records {
+			record := &monthlyWorkTime.Records[i]
+			if exported := worktime.NewExportedRecord(args.projectName, record); exported != nil {
+				result.Records = append(result.Records, *exported)
+			}
+			if name, holiday := calendar.GetHoliday(record.Date); holiday {
+				result.Holidays[record.Date.String()] = name
+			}
+		}
+		workingDays, expected := calendar.GetExpected(today.Year, today.Month)
+		result.ExpectedMinutes, result.WorkingDays = worktime.ToMinutes(expected), workingDays
+		if pending, err := newJournal(config).Load(); err == nil {
+			result.Pending = len(pending)
+		}
+		printResult(result)
+		return
+	}
+
+	// Shortage of breaks is marked with "!" if it is not deducted
+	formatBreakShortage := func(r *worktime.WorkTimeRecord) string {
+		if r.BreakShortage == 0 {
+			return ""
+		}
+		if r.BreakDeduction > 0 {
+			return "-" + formatDuration(r.BreakDeduction, false)
+		}
+		return "!" + formatDuration(r.BreakShortage, false)
+	}
+
+	for _, record := range monthlyWorkTime.Records {
+		fmt.Print(formatDate(record.Date, calendar))
+		for i := 0; i < periodCount; i++ {
+			var p worktime.Period
+			if i < len(record.Periods) {
+				p = record.Periods[i]
+			}
+			fmt.Printf("  %11s", formatPeriod(p))
+		}
+		fmt.Printf("    %5s", formatDuration(record.GetDuration(), true))
+		if len(adjustments) > 0 {
+			fmt.Printf(" %6s", formatAdjustment(adjustments[record.Date.Day]))
+		}
+		if showBreaks {
+			fmt.Printf(" %6s", formatBreakShortage(&record))
+		}
+		fmt.Printf(" | %s", formatTravelExpense(record.TravelExpense))
+		if name, holiday := calendar.GetHoliday(record.Date); holiday {
+			fmt.Printf("  (%s)", name)
+		}
+		fmt.Println()
+	}
+
+	separatorWidth := 22 + 13*periodCount
+	if len(adjustments) > 0 {
+		separatorWidth += 7
+	}
+	if showBreaks {
+		separatorWidth += 7
+	}
+	fmt.Println(strings.Repeat("-", separatorWidth))
+	fmt.Printf("%-*s%6s\n", 8+13*periodCount, "Total:",
 		formatDuration(monthlyWorkTime.GetDuration(), false))
+	if len(adjustments) > 0 {
+		fmt.Printf("%-*s%6s\n", 8+13*periodCount, "Rounding:", formatAdjustment(totalAdjustment))
+	}
+	if showBreaks {
+		shortDays := 0
+		for _, record := range monthlyWorkTime.Records {
+			if record.BreakShortage > 0 && record.BreakDeduction == 0 {
+				shortDays++
+			}
+		}
+		if d := monthlyWorkTime.GetBreakDeduction(); d > 0 {
+			fmt.Printf("%-*s%6s\n", 8+13*periodCount, "Break:", "-"+formatDuration(d, false))
+		}
+		if shortDays > 0 {
+			log.Printf("Breaks are shorter than required on %d days", shortDays)
+		}
+	}
+	workingDays, expected := calendar.GetExpected(today.Year, today.Month)
+	fmt.Printf("%-*s%6s  (%d working days)\n", 8+13*periodCount, "Expected:",
+		formatDuration(expected, false), workingDays)
+
+	if pending, err := newJournal(config).Load(); err == nil && len(pending) > 0 {
+		log.Printf("%d entries are waiting for sync", len(pending))
+	}
+}
+
+// formatDate formats the date with the weekday, enclosed in brackets
+// instead of parentheses if it is a holiday.
+func formatDate(d *worktime.Date, calendar *worktime.Calendar) string {
+	w := []string{"日", "月", "火", "水", "木", "金", "土"}[d.GetWeekday()]
+	if _, holiday := calendar.GetHoliday(d); holiday {
+		return fmt.Sprintf("%2d/%2d [%s]", d.Month, d.Day, w)
+	}
+	return fmt.Sprintf("%2d/%2d (%s)", d.Month, d.Day, w)
+}
+
+func showBreakdown(w *worktime.WorkTime, projectName string, today *worktime.Date, config *configuration.Config) {
+	breakdown, err := w.GetBreakdown(projectName, today.Year, today.Month)
+	if err != nil {
+		log.Fatal(err)
+	}
+	calendar, err := worktime.GetCalendar(config, projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if outputFormat != "table" {
+		toBreakdownMinutes := func(date string, b *worktime.Breakdown) breakdownMinutes {
+			return breakdownMinutes{date, worktime.ToMinutes(b.Regular), worktime.ToMinutes(b.Overtime), worktime.ToMinutes(b.LateNight), worktime.ToMinutes(b.Holiday)}
+		}
+		result := &breakdownResult{Project: projectName, Year: breakdown.Year, Month: breakdown.Month, Days: []breakdownMinutes{}}
+		for _, day := range breakdown.Days {
+			result.Days = append(result.Days, toBreakdownMinutes(day.Date.String(), &day.Breakdown))
+		}
+		result.Total = toBreakdownMinutes("", &breakdown.Total)
+		printResult(result)
+		return
+	}
+
+	formatDuration := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	formatBreakdown := func(b *worktime.Breakdown) string {
+		return fmt.Sprintf("%9s %9s %9s %9s", formatDuration(b.Regular), formatDuration(b.Overtime),
+			formatDuration(b.LateNight), formatDuration(b.Holiday))
+	}
+
+	fmt.Printf("%-10s %9s %9s %9s %9s\n", "", "Regular", "Overtime", "Night", "Holiday")
+	for _, day := range breakdown.Days {
+		fmt.Printf("%s %s\n", formatDate(day.Date, calendar), formatBreakdown(&day.Breakdown))
+	}
+	fmt.Println(strings.Repeat("-", 50))
+	fmt.Printf("%-10s %s\n", "Total:", formatBreakdown(&breakdown.Total))
 }
 
 func doStart(args *startCmdArgs, config *configuration.Config) {
-	s := spreadsheet.New(config)
-	w := worktime.New(s, config)
+	w := worktime.New(newStorage(config), config)
 
-	now := time.Now()
+	date, t := projectNow(args.projectName, config)
+	date = resolveDate(args.date, date)
+	if args.time != "" {
+		var err error
+		t, err = worktime.ParseHHMM(args.time)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	t = roundTime(t, "start", args.projectName, date, config)
 
-	var t *worktime.Time
-	var err error
+	entry := &worktime.JournalEntry{
+		Operation:   "start",
+		ProjectName: args.projectName,
+		Date:        *date,
+		Time:        t,
+	}
+	printEntry(w, entry, applyOrQueue(w, entry, config))
+}
+
+func doEnd(args *endCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+
+	date, t := projectNow(args.projectName, config)
+	date = resolveDate(args.date, date)
 	if args.time != "" {
+		var err error
 		t, err = worktime.ParseHHMM(args.time)
 		if err != nil {
 			log.Fatal(err)
 		}
+	}
+	t = roundTime(t, "end", args.projectName, date, config)
+
+	entry := &worktime.JournalEntry{
+		Operation:   "end",
+		ProjectName: args.projectName,
+		Date:        *date,
+		Time:        t,
+	}
+	printEntry(w, entry, applyOrQueue(w, entry, config))
+}
+
+func doTravel(args *travelCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+
+	date, _ := projectNow(args.projectName, config)
+	date = resolveDate(args.date, date)
+
+	entry := &worktime.JournalEntry{
+		Operation:   "travel",
+		ProjectName: args.projectName,
+		Date:        *date,
+		Expense:     args.expense,
+		Note:        args.note,
+	}
+	printEntry(w, entry, applyOrQueue(w, entry, config))
+}
+
+func doEdit(args *editCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+
+	date, _ := projectNow(args.projectName, config)
+	date = resolveDate(args.date, date)
+
+	if args.clear {
+		if args.start != "" || args.end != "" {
+			log.Fatal("-clear cannot be used with -start or -end")
+		}
+		if err := w.ClearPeriod(args.projectName, date, args.slot-1); err != nil {
+			log.Fatal(err)
+		}
+		if outputFormat != "table" {
+			printResult(getChange(w, "clear", args.projectName, date, args.slot-1, []string{"start", "end"}))
+		}
+		return
+	}
+
+	var start, end *worktime.Time
+	var err error
+	if args.start != "" {
+		if start, err = worktime.ParseHHMM(args.start); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if args.end != "" {
+		if end, err = worktime.ParseHHMM(args.end); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if start == nil && end == nil {
+		log.Fatal("Either -start, -end or -clear is required")
+	}
+	if err := w.EditPeriod(args.projectName, date, args.slot-1, start, end); err != nil {
+		log.Fatal(err)
+	}
+	if outputFormat != "table" {
+		var columns []string
+		if start != nil {
+			columns = append(columns, "start")
+		}
+		if end != nil {
+			columns = append(columns, "end")
+		}
+		printResult(getChange(w, "edit", args.projectName, date, args.slot-1, columns))
+	}
+}
+
+func doCheck(args *checkCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+	w.SetStrict(args.strict)
+
+	now := time.Now()
+	year, month := resolveMonth(args.month, now.Year(), int(now.Month()))
+
+	issues, err := w.Check(year, month, now)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if outputFormat != "table" {
+		result := &checkResult{Year: year, Month: month, Issues: []checkIssue{}}
+		for _, issue := range issues {
+			i := checkIssue{Kind: issue.Kind, Project: issue.ProjectName, Message: issue.Message}
+			if issue.Date != nil {
+				i.Date, i.Slot = issue.Date.String(), issue.Slot+1
+			}
+			result.Issues = append(result.Issues, i)
+		}
+		printResult(result)
 	} else {
-		t = &worktime.Time{now.Hour(), now.Minute()}
+		for _, issue := range issues {
+			fmt.Println(issue.String())
+		}
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+func doReport(args *reportCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+
+	sheet, err := config.FindSpreadsheet(args.projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	today, _ := projectNow(args.projectName, config)
+	year, month := resolveMonth(args.month, today.Year, today.Month)
+
+	monthlyWorkTime, err := w.Get(args.projectName, year, month)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	for _, d := range monthlyWorkTime.Diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", d.String())
+	}
+	calendar, err := worktime.GetCalendar(config, args.projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	timesheet := &report.Timesheet{
+		ProjectName: args.projectName,
+		Client:      sheet.Client,
+		Worker:      config.Worker,
+		WorkTime:    monthlyWorkTime,
+		Calendar:    calendar,
+	}
+
+	if args.format != "pdf" {
+		log.Fatalf("Invalid format: %s", args.format)
 	}
-	t = t.RoundTime()
+	output := args.output
+	if output == "" {
+		output = fmt.Sprintf("%s-%04d-%02d.pdf", args.projectName, year, month)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := timesheet.WritePDF(f, config.GetFontPath()); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(output)
+}
 
-	err = w.SetStart(args.projectName,
-		&worktime.Date{now.Year(), int(now.Month()), now.Day()},
-		t)
+func doInvoice(args *invoiceCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+
+	sheet, err := config.FindSpreadsheet(args.projectName)
 	if err != nil {
 		log.Fatal(err)
 	}
+	policy, err := worktime.GetBillingPolicy(config, args.projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if args.format != "pdf" && args.format != "text" {
+		log.Fatalf("Invalid format: %s", args.format)
+	}
+	today, _ := projectNow(args.projectName, config)
+	year, month := resolveMonth(args.month, today.Year, today.Month)
+
+	monthlyWorkTime, err := w.Get(args.projectName, year, month)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	for _, d := range monthlyWorkTime.Diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", d.String())
+	}
+	for _, record := range monthlyWorkTime.Records {
+		for _, p := range record.Periods {
+			if !p.IsEmpty() && p.IsEndEmpty() {
+				fmt.Fprintf(os.Stderr, "Warning: %v has a period not ended, which is not billed\n", record.Date)
+			}
+		}
+	}
+
+	registry := worktime.NewInvoiceRegistry(filepath.Join(config.Dir, "invoices.json"))
+	number, err := registry.GetNumber(args.projectName, year, month, policy)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	invoice := &report.Invoice{
+		Number:             number,
+		IssueDate:          today,
+		ProjectName:        args.projectName,
+		Client:             sheet.Client,
+		Worker:             config.Worker,
+		RegistrationNumber: config.RegistrationNumber,
+		Year:               year,
+		Month:              month,
+		Policy:             policy,
+		Bill:               policy.Calculate(monthlyWorkTime),
+	}
+
+	if args.format == "text" {
+		out := os.Stdout
+		if args.output != "" {
+			if out, err = os.Create(args.output); err != nil {
+				log.Fatal(err)
+			}
+			defer out.Close()
+		}
+		if err := invoice.WriteText(out); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	output := args.output
+	if output == "" {
+		output = fmt.Sprintf("invoice-%s.pdf", number)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := invoice.WritePDF(f, config.GetFontPath()); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(output)
 }
 
-func doEnd(args *endCmdArgs, config *configuration.Config) {
-	s := spreadsheet.New(config)
-	w := worktime.New(s, config)
+func doExport(args *exportCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
 
-	now := time.Now()
+	projectNames := args.projectNames
+	if len(projectNames) == 0 {
+		for _, sheet := range config.Spreadsheets {
+			projectNames = append(projectNames, sheet.Name)
+		}
+	}
+	if len(projectNames) == 0 {
+		log.Fatal("No project is configured")
+	}
 
-	var t *worktime.Time
-	var err error
+	today, _ := projectNow(projectNames[0], config)
+	year, month := resolveMonth(args.month, today.Year, today.Month)
+	from := &worktime.Date{Year: year, Month: month, Day: 1}
+	to := &worktime.Date{Year: year, Month: month, Day: 1}
+	for !to.IsLastDayOfMonth() {
+		to = to.AddDays(1)
+	}
+	if args.from != "" {
+		from = resolveDate(args.from, today)
+	}
+	if args.to != "" {
+		to = resolveDate(args.to, today)
+	}
+
+	export, issues, err := w.Export(projectNames, from, to)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", issue.String())
+	}
+
+	out := os.Stdout
+	if args.output != "" {
+		out, err = os.Create(args.output)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	switch args.format {
+	case "csv":
+		err = export.WriteCSV(out)
+	case "json":
+		err = export.WriteJSON(out)
+	default:
+		err = fmt.Errorf("Invalid format: %s", args.format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func doImport(args *importCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+
+	f, err := os.Open(args.path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []worktime.ImportEntry
+	switch args.format {
+	case "csv":
+		mapping, err := worktime.ParseCSVMapping(args.mapping)
+		if err != nil {
+			log.Fatal(err)
+		}
+		entries, err = worktime.ReadImportCSV(f, mapping, args.projectName, config)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "json":
+		entries, err = worktime.ReadImportJSON(f, config)
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("Invalid format: %s", args.format)
+	}
+
+	changes, err := w.PlanImport(entries)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	counts := map[string]int{}
+	result := &importResult{Changes: []importChange{}, DryRun: args.dryRun}
+	for _, c := range changes {
+		counts[c.Action]++
+		if outputFormat == "table" {
+			fmt.Println(c.String())
+			continue
+		}
+		e := &c.Entry
+		change := importChange{Action: c.Action, Project: e.ProjectName, Date: e.Date.String(), Reason: c.Reason}
+		if e.TravelExpense != nil {
+			change.Expense, change.Note = e.TravelExpense.Expense, e.TravelExpense.Note
+		} else {
+			change.Start = fmt.Sprintf("%d:%02d", e.Start.Hour, e.Start.Minute)
+			if e.End != nil {
+				change.End = fmt.Sprintf("%d:%02d", e.End.Hour, e.End.Minute)
+			}
+			if c.Action == worktime.ImportAdd {
+				change.Slot = c.Slot + 1
+			}
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	result.Added, result.Skipped, result.Conflicts = counts[worktime.ImportAdd], counts[worktime.ImportSkip], counts[worktime.ImportConflict]
+	if outputFormat == "table" {
+		fmt.Printf("%d to add, %d already recorded, %d conflicts\n", result.Added, result.Skipped, result.Conflicts)
+	}
+
+	if args.dryRun || result.Added == 0 {
+		if outputFormat != "table" {
+			printResult(result)
+		}
+		return
+	}
+	if result.Conflicts > 0 && !args.skipConflicts {
+		if outputFormat != "table" {
+			printResult(result)
+		}
+		log.Fatal("Resolve the conflicts or use -skip-conflicts")
+	}
+
+	err = w.ApplyImport(changes, args.batchSize, func(done int) {
+		log.Printf("Imported %d periods", done)
+	})
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if outputFormat != "table" {
+		result.Written = result.Added
+		printResult(result)
+	}
+}
+
+func doSwitch(args *switchCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+
+	date, t := projectNow(args.projectName, config)
 	if args.time != "" {
+		var err error
 		t, err = worktime.ParseHHMM(args.time)
 		if err != nil {
 			log.Fatal(err)
 		}
-	} else {
-		t = &worktime.Time{now.Hour(), now.Minute()}
 	}
-	t = t.RoundTime()
+	location, err := worktime.GetLocation(config, args.projectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	at := time.Date(date.Year, time.Month(date.Month), date.Day, t.Hour, t.Minute, 0, 0, location)
+
+	// The end of another project is at the same instant in its time zone
+	localAt := func(projectName string) (*worktime.Date, *worktime.Time) {
+		location, err := worktime.GetLocation(config, projectName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		local := at.In(location)
+		return &worktime.Date{Year: local.Year(), Month: int(local.Month()), Day: local.Day()},
+			&worktime.Time{Hour: local.Hour(), Minute: local.Minute()}
+	}
+	endAt := func(projectName string) (*worktime.Date, *worktime.Time) {
+		policy, err := worktime.GetRoundingPolicy(config, projectName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		d, t := localAt(projectName)
+		return d, policy.RoundEnd(t)
+	}
+	t = roundTime(t, "start", args.projectName, date, config)
 
-	err = w.SetEnd(args.projectName,
-		&worktime.Date{now.Year(), int(now.Month()), now.Day()},
-		t)
+	ended, err := w.Switch(args.projectName, date, t, endAt)
 	if err != nil {
 		log.Fatal(err)
 	}
+	var endDate *worktime.Date
+	var endTime *worktime.Time
+	if ended != "" {
+		// Records the rounding of the end only for the project ended
+		endDate, endTime = localAt(ended)
+		endTime = roundTime(endTime, "end", ended, endDate, config)
+	}
+
+	if outputFormat != "table" {
+		result := &switchResult{}
+		if ended != "" {
+			endedDate, slot, err := w.FindSlot(ended, endDate, "end", endTime)
+			if err != nil {
+				log.Fatalf("%+v", err)
+			}
+			result.Ended = getChange(w, "end", ended, endedDate, slot, []string{"end"})
+		}
+		_, slot, err := w.FindSlot(args.projectName, date, "start", t)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		result.Started = getChange(w, "start", args.projectName, date, slot, []string{"start"})
+		printResult(result)
+		return
+	}
+	if ended != "" {
+		fmt.Printf("%s %2d:%02d -> %s  %2d:%02d\n", ended, endTime.Hour, endTime.Minute, args.projectName, t.Hour, t.Minute)
+	} else {
+		fmt.Printf("%s  %2d:%02d\n", args.projectName, t.Hour, t.Minute)
+	}
 }
 
-func doTravel(args *travelCmdArgs, config *configuration.Config) {
-	s := spreadsheet.New(config)
-	w := worktime.New(s, config)
+func doStatus(args *statusCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
 
 	now := time.Now()
 
-	err := w.SetTravelExpense(args.projectName,
-		&worktime.Date{now.Year(), int(now.Month()), now.Day()},
-		args.expense,
-		args.note)
+	running := false
+	results := []statusResult{}
+	for _, sheet := range config.Spreadsheets {
+		today, _ := projectNow(sheet.Name, config)
+		period, err := w.GetOpenPeriod(sheet.Name, today)
+		if err != nil {
+			log.Printf("%s: %v", sheet.Name, err)
+			continue
+		}
+		if period == nil {
+			continue
+		}
+		running = true
+		elapsed := now.Sub(period.Start)
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		if outputFormat != "table" {
+			results = append(results, statusResult{sheet.Name, period.Start.Format(time.RFC3339), worktime.ToMinutes(elapsed)})
+			continue
+		}
+		fmt.Printf("%s  %2d:%02d-  %d:%02d\n", sheet.Name,
+			period.Start.Hour(), period.Start.Minute(),
+			int(elapsed.Hours()), int(elapsed.Minutes())%60)
+	}
+
+	if outputFormat != "table" {
+		printResult(results)
+		return
+	}
+	if !running {
+		fmt.Println("No running period")
+	}
+}
+
+func doSync(args *syncCmdArgs, config *configuration.Config) {
+	w := worktime.New(newStorage(config), config)
+	journal := newJournal(config)
+
+	entries, err := journal.Load()
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("%+v", err)
+	}
+	result := &syncResult{Synced: []string{}}
+	if args.skip && len(entries) > 0 {
+		result.Skipped = entries[0].String()
+		if outputFormat == "table" {
+			fmt.Printf("Skipped: %s\n", &entries[0])
+		}
+		entries = entries[1:]
+	}
+
+	for len(entries) > 0 {
+		if err := w.Replay(&entries[0]); err != nil {
+			if err := journal.Save(entries); err != nil {
+				log.Fatalf("%+v", err)
+			}
+			log.Fatalf("%v\n%d entries remain", err, len(entries))
+		}
+		result.Synced = append(result.Synced, entries[0].String())
+		if outputFormat == "table" {
+			fmt.Printf("Synced: %s\n", &entries[0])
+		}
+		entries = entries[1:]
+	}
+
+	if err := journal.Save(entries); err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if outputFormat != "table" {
+		printResult(result)
 	}
 }
 
 func doLink(args *linkCmdArgs, config *configuration.Config) {
 	spreadsheetId, err := config.FindSpreadsheetId(args.projectName)
 	if err != nil {
-		log.Fatalf("%+w", err)
+		log.Fatalf("%+v", err)
 	}
 
 	s := spreadsheet.New(config)
 	link := s.GetSpreadsheetLink(spreadsheetId)
 
+	if outputFormat != "table" {
+		printResult(&linkResult{Project: args.projectName, Link: link})
+		return
+	}
 	fmt.Println(link)
 }
 
@@ -195,33 +1213,51 @@ func main() {
 	endCmd := flag.NewFlagSet("end", flag.ExitOnError)
 	travelCmd := flag.NewFlagSet("travel", flag.ExitOnError)
 	linkCmd := flag.NewFlagSet("link", flag.ExitOnError)
+	editCmd := flag.NewFlagSet("edit", flag.ExitOnError)
+	checkCmd := flag.NewFlagSet("check", flag.ExitOnError)
+	reportCmd := flag.NewFlagSet("report", flag.ExitOnError)
+	invoiceCmd := flag.NewFlagSet("invoice", flag.ExitOnError)
+	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
+	importCmd := flag.NewFlagSet("import", flag.ExitOnError)
+	syncCmd := flag.NewFlagSet("sync", flag.ExitOnError)
+	statusCmd := flag.NewFlagSet("status", flag.ExitOnError)
+	switchCmd := flag.NewFlagSet("switch", flag.ExitOnError)
 
-	if len(os.Args) < 2 {
-		log.Fatalf("Usage: %s COMMAND [ARGS]", os.Args[0])
+	flag.StringVar(&outputFormat, "output", "table", "table, json or yaml")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		log.Fatalf("Usage: %s [-output table|json|yaml] COMMAND [ARGS]", os.Args[0])
+	}
+	if outputFormat != "table" && outputFormat != "json" && outputFormat != "yaml" {
+		log.Fatalf("Invalid output: %s", outputFormat)
 	}
+	cmdArgs := flag.Args()[1:]
 
-	switch os.Args[1] {
+	switch flag.Arg(0) {
 	case "show":
-		showCmd.Parse(os.Args[2:])
-		args := showCmdArgs{
-			projectName: showCmd.Arg(0),
-		}
+		var args showCmdArgs
+		showCmd.BoolVar(&args.breakdown, "breakdown", false, "Show regular, overtime, late-night and holiday work")
+		showCmd.Parse(cmdArgs)
+		args.projectName = showCmd.Arg(0)
 		doShow(&args, config)
 	case "start":
 		var args startCmdArgs
 		startCmd.StringVar(&args.time, "time", "", "HH:MM")
-		startCmd.Parse(os.Args[2:])
+		startCmd.StringVar(&args.date, "date", "", "YYYY-MM-DD, yesterday or -Nd")
+		startCmd.Parse(cmdArgs)
 		args.projectName = startCmd.Arg(0)
 		doStart(&args, config)
 	case "end":
 		var args endCmdArgs
 		endCmd.StringVar(&args.time, "time", "", "HH:MM")
-		endCmd.Parse(os.Args[2:])
+		endCmd.StringVar(&args.date, "date", "", "YYYY-MM-DD, yesterday or -Nd")
+		endCmd.Parse(cmdArgs)
 		args.projectName = endCmd.Arg(0)
 		doEnd(&args, config)
 	case "travel":
 		var args travelCmdArgs
-		travelCmd.Parse(os.Args[2:])
+		travelCmd.StringVar(&args.date, "date", "", "YYYY-MM-DD, yesterday or -Nd")
+		travelCmd.Parse(cmdArgs)
 		args.projectName = travelCmd.Arg(0)
 		expense, err := strconv.Atoi(travelCmd.Arg(1))
 		if err != nil {
@@ -230,12 +1266,80 @@ func main() {
 		args.expense = expense
 		args.note = travelCmd.Arg(2)
 		doTravel(&args, config)
+	case "edit":
+		var args editCmdArgs
+		editCmd.StringVar(&args.date, "date", "", "YYYY-MM-DD, yesterday or -Nd")
+		editCmd.IntVar(&args.slot, "slot", 1, "1-based period number of the day")
+		editCmd.StringVar(&args.start, "start", "", "HH:MM")
+		editCmd.StringVar(&args.end, "end", "", "HH:MM")
+		editCmd.BoolVar(&args.clear, "clear", false, "Clear the period")
+		editCmd.Parse(cmdArgs)
+		args.projectName = editCmd.Arg(0)
+		doEdit(&args, config)
+	case "check":
+		var args checkCmdArgs
+		checkCmd.StringVar(&args.month, "month", "", "YYYY-MM (default: this month)")
+		checkCmd.BoolVar(&args.strict, "strict", false, "Fail on the first month which has invalid rows")
+		checkCmd.Parse(cmdArgs)
+		doCheck(&args, config)
+	case "report":
+		var args reportCmdArgs
+		reportCmd.StringVar(&args.month, "month", "", "YYYY-MM (default: this month)")
+		reportCmd.StringVar(&args.format, "format", "pdf", "pdf")
+		reportCmd.StringVar(&args.output, "o", "", "Output file (default: PROJECT-YYYY-MM.pdf)")
+		reportCmd.Parse(cmdArgs)
+		args.projectName = reportCmd.Arg(0)
+		doReport(&args, config)
+	case "invoice":
+		var args invoiceCmdArgs
+		invoiceCmd.StringVar(&args.month, "month", "", "YYYY-MM (default: this month)")
+		invoiceCmd.StringVar(&args.format, "format", "pdf", "pdf or text")
+		invoiceCmd.StringVar(&args.output, "o", "", "Output file (default: invoice-NUMBER.pdf, or standard output for text)")
+		invoiceCmd.Parse(cmdArgs)
+		args.projectName = invoiceCmd.Arg(0)
+		doInvoice(&args, config)
+	case "export":
+		var args exportCmdArgs
+		exportCmd.StringVar(&args.month, "month", "", "YYYY-MM (default: this month)")
+		exportCmd.StringVar(&args.from, "from", "", "First date, overriding -month (YYYY-MM-DD, yesterday or -Nd)")
+		exportCmd.StringVar(&args.to, "to", "", "Last date, overriding -month (YYYY-MM-DD, yesterday or -Nd)")
+		exportCmd.StringVar(&args.format, "format", "csv", "csv or json")
+		exportCmd.StringVar(&args.output, "o", "", "Output file (default: standard output)")
+		exportCmd.Parse(cmdArgs)
+		args.projectNames = exportCmd.Args()
+		doExport(&args, config)
+	case "import":
+		var args importCmdArgs
+		importCmd.StringVar(&args.format, "format", "csv", "csv or json")
+		importCmd.StringVar(&args.mapping, "map", "", "Columns of CSV such as \"project=Project,date=Start date,start=Start time,end=End time\"")
+		importCmd.StringVar(&args.projectName, "project", "", "Project of all rows of CSV")
+		importCmd.BoolVar(&args.dryRun, "dry-run", false, "Show changes without writing")
+		importCmd.BoolVar(&args.skipConflicts, "skip-conflicts", false, "Import entries other than conflicts")
+		importCmd.IntVar(&args.batchSize, "batch", 50, "Periods written at once")
+		importCmd.Parse(cmdArgs)
+		args.path = importCmd.Arg(0)
+		doImport(&args, config)
 	case "link":
 		var args linkCmdArgs
-		linkCmd.Parse(os.Args[2:])
+		linkCmd.Parse(cmdArgs)
 		args.projectName = linkCmd.Arg(0)
 		doLink(&args, config)
+	case "switch":
+		var args switchCmdArgs
+		switchCmd.StringVar(&args.time, "time", "", "HH:MM")
+		switchCmd.Parse(cmdArgs)
+		args.projectName = switchCmd.Arg(0)
+		doSwitch(&args, config)
+	case "status":
+		var args statusCmdArgs
+		statusCmd.Parse(cmdArgs)
+		doStatus(&args, config)
+	case "sync":
+		var args syncCmdArgs
+		syncCmd.BoolVar(&args.skip, "skip", false, "Discard the first pending entry")
+		syncCmd.Parse(cmdArgs)
+		doSync(&args, config)
 	default:
-		log.Fatalf("Invalid command: %s", os.Args[1])
+		log.Fatalf("Invalid command: %s", flag.Arg(0))
 	}
 }
@@ -0,0 +1,167 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/worktime"
+)
+
+// Invoice is a bill of a month of a project to a client.
+type Invoice struct {
+	Number      string
+	IssueDate   *worktime.Date
+	ProjectName string
+	Client      string
+	Worker      string
+	// RegistrationNumber is the number of the registered invoice issuer
+	RegistrationNumber string
+	Year, Month        int
+	Policy             *worktime.BillingPolicy
+	Bill               *worktime.Bill
+}
+
+type invoiceLine struct {
+	description string
+	quantity    string
+	unitPrice   string
+	amount      string
+}
+
+func (this *Invoice) getDueDate() *worktime.Date {
+	return this.IssueDate.AddDays(this.Policy.DueDays)
+}
+
+func (this *Invoice) getLines() []invoiceLine {
+	bill := this.Bill
+	p := this.Policy
+	lines := []invoiceLine{{
+		description: fmt.Sprintf("Work %04d-%02d (%s)", this.Year, this.Month, this.ProjectName),
+		quantity:    fmt.Sprintf("%.2f h", bill.BilledDuration.Hours()),
+		unitPrice:   p.FormatAmount(bill.UnitPrice),
+		amount:      p.FormatAmount(bill.Labor),
+	}}
+	if bill.TravelDays > 0 {
+		days := fmt.Sprintf("%d days", bill.TravelDays)
+		if bill.TravelDays == 1 {
+			days = "1 day"
+		}
+		lines = append(lines, invoiceLine{
+			description: "Travel expenses",
+			quantity:    days,
+			amount:      p.FormatAmount(bill.TravelExpense),
+		})
+	}
+	return lines
+}
+
+// getTotals returns pairs of labels and amounts below the lines.
+func (this *Invoice) getTotals() [][2]string {
+	p := this.Policy
+	return [][2]string{
+		{"Subtotal", p.FormatAmount(this.Bill.Subtotal)},
+		{fmt.Sprintf("Consumption tax (%v%%)", p.TaxRate), p.FormatAmount(this.Bill.Tax)},
+		{fmt.Sprintf("Total (%s)", p.Currency), p.FormatAmount(this.Bill.Total)},
+	}
+}
+
+func (this *Invoice) getFields() [][2]string {
+	fields := [][2]string{
+		{"Invoice No.", this.Number},
+		{"Issued", this.IssueDate.String()},
+		{"Due", this.getDueDate().String()},
+		{"To", this.Client},
+		{"From", this.Worker},
+	}
+	if this.RegistrationNumber != "" {
+		fields = append(fields, [2]string{"Registration No.", this.RegistrationNumber})
+	}
+	return fields
+}
+
+// WriteText renders the invoice as plain text in columns.
+func (this *Invoice) WriteText(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("INVOICE\n\n")
+	for _, field := range this.getFields() {
+		fmt.Fprintf(&b, "%-17s %s\n", field[0]+":", field[1])
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "%-40s %10s %12s %14s\n", "Description", "Quantity", "Unit price", "Amount")
+	b.WriteString(strings.Repeat("-", 79) + "\n")
+	for _, line := range this.getLines() {
+		fmt.Fprintf(&b, "%-40s %10s %12s %14s\n", line.description, line.quantity, line.unitPrice, line.amount)
+	}
+	b.WriteString(strings.Repeat("-", 79) + "\n")
+	for _, total := range this.getTotals() {
+		fmt.Fprintf(&b, "%64s %14s\n", total[0], total[1])
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return xerrors.Errorf("Unable to write invoice: %w", err)
+	}
+	return nil
+}
+
+// WritePDF renders the invoice as a PDF of a page.
+func (this *Invoice) WritePDF(w io.Writer, fontPath string) error {
+	doc, err := newDocument(fontPath)
+	if err != nil {
+		return err
+	}
+	pdf := doc.pdf
+
+	doc.setFont(18)
+	doc.cell(0, 12, "INVOICE", "", "C")
+	pdf.Ln(16)
+
+	doc.setFont(10)
+	for _, field := range this.getFields() {
+		doc.cell(32, 6, field[0]+":", "", "L")
+		doc.cell(0, 6, field[1], "", "L")
+		pdf.Ln(6)
+	}
+	pdf.Ln(6)
+
+	doc.setFont(12)
+	doc.cell(0, 8, fmt.Sprintf("Amount due: %s %s", this.Policy.FormatAmount(this.Bill.Total), this.Policy.Currency), "B", "L")
+	pdf.Ln(14)
+
+	const descriptionWidth, quantityWidth, unitPriceWidth, amountWidth, rowHeight = 90.0, 25.0, 30.0, 35.0, 7.0
+	doc.setFont(9)
+	pdf.SetFillColor(230, 230, 230)
+	for _, header := range []struct {
+		w    float64
+		text string
+	}{
+		{descriptionWidth, "Description"},
+		{quantityWidth, "Quantity"},
+		{unitPriceWidth, "Unit price"},
+		{amountWidth, "Amount"},
+	} {
+		pdf.CellFormat(header.w, rowHeight, doc.translate(header.text), "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(rowHeight)
+	for _, line := range this.getLines() {
+		doc.cell(descriptionWidth, rowHeight, line.description, "1", "L")
+		doc.cell(quantityWidth, rowHeight, line.quantity, "1", "R")
+		doc.cell(unitPriceWidth, rowHeight, line.unitPrice, "1", "R")
+		doc.cell(amountWidth, rowHeight, line.amount, "1", "R")
+		pdf.Ln(rowHeight)
+	}
+	for _, total := range this.getTotals() {
+		doc.cell(descriptionWidth, rowHeight, "", "", "L")
+		doc.cell(quantityWidth+unitPriceWidth, rowHeight, total[0], "1", "R")
+		doc.cell(amountWidth, rowHeight, total[1], "1", "R")
+		pdf.Ln(rowHeight)
+	}
+
+	pdf.Ln(6)
+	doc.setFont(8)
+	doc.cell(0, 5, fmt.Sprintf("Work of %s after deduction of breaks, billed in units of %v.",
+		formatDuration(this.Bill.Duration), this.Policy.Unit), "", "L")
+	pdf.Ln(5)
+	return doc.write(w)
+}
@@ -0,0 +1,265 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/xerrors"
+
+	"work-time-logging/worktime"
+)
+
+// Timesheet is a monthly work time record to be submitted to a client.
+type Timesheet struct {
+	ProjectName string
+	Client      string
+	Worker      string
+	WorkTime    *worktime.MonthlyWorkTime
+	Calendar    *worktime.Calendar
+}
+
+// document wraps gofpdf with a font which may be a UTF-8 TrueType font.
+type document struct {
+	pdf       *gofpdf.Fpdf
+	family    string
+	translate func(string) string
+	unicode   bool
+}
+
+// newDocument returns an A4 document. Text other than Latin-1 is not
+// rendered unless fontPath is given.
+func newDocument(fontPath string) (*document, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	doc := &document{pdf: pdf, family: "Helvetica", translate: pdf.UnicodeTranslatorFromDescriptor("")}
+	if fontPath != "" {
+		pdf.AddUTF8Font("custom", "", fontPath)
+		if err := pdf.Error(); err != nil {
+			return nil, xerrors.Errorf("Unable to load font: %w", err)
+		}
+		doc.family = "custom"
+		doc.translate = func(s string) string { return s }
+		doc.unicode = true
+	}
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+	return doc, nil
+}
+
+func (this *document) setFont(size float64) {
+	this.pdf.SetFont(this.family, "", size)
+}
+
+func (this *document) cell(w, h float64, text, border, align string) {
+	this.pdf.CellFormat(w, h, this.translate(text), border, 0, align, false, 0, "")
+}
+
+func (this *document) write(w io.Writer) error {
+	if err := this.pdf.Output(w); err != nil {
+		return xerrors.Errorf("Unable to write PDF: %w", err)
+	}
+	return nil
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func formatPeriod(p worktime.Period) string {
+	if p.IsEmpty() {
+		return ""
+	}
+	start := fmt.Sprintf("%d:%02d", p.Start.Hour(), p.Start.Minute())
+	if p.IsEndEmpty() {
+		return start + "-"
+	}
+	endHour := p.End.Hour()
+	if p.Start.Day() != p.End.Day() {
+		endHour += 24
+	}
+	return fmt.Sprintf("%s-%d:%02d", start, endHour, p.End.Minute())
+}
+
+func (this *document) formatWeekday(date *worktime.Date) string {
+	if this.unicode {
+		return []string{"日", "月", "火", "水", "木", "金", "土"}[date.GetWeekday()]
+	}
+	return date.GetWeekday().String()[:3]
+}
+
+type timesheetRow struct {
+	date, weekday string
+	periods       []string
+	hours         string
+	note, expense string
+	holiday       bool
+}
+
+func (this *Timesheet) getPeriodCount() int {
+	periodCount := 0
+	for _, record := range this.WorkTime.Records {
+		if len(record.Periods) > periodCount {
+			periodCount = len(record.Periods)
+		}
+	}
+	return periodCount
+}
+
+// getRows returns a row of each day with as many periods as the busiest day.
+func (this *Timesheet) getRows(doc *document) []timesheetRow {
+	periodCount := this.getPeriodCount()
+	var rows []timesheetRow
+	for _, record := range this.WorkTime.Records {
+		row := timesheetRow{
+			date:    fmt.Sprintf("%d/%d", record.Date.Month, record.Date.Day),
+			weekday: doc.formatWeekday(record.Date),
+		}
+		if this.Calendar != nil {
+			row.holiday = !this.Calendar.IsWorkingDay(record.Date)
+		}
+		for i := 0; i < periodCount; i++ {
+			var p worktime.Period
+			if i < len(record.Periods) {
+				p = record.Periods[i]
+			}
+			row.periods = append(row.periods, formatPeriod(p))
+		}
+		if d := record.GetDuration(); d > 0 {
+			row.hours = formatDuration(d)
+		}
+		if record.TravelExpense != nil {
+			row.note = record.TravelExpense.Note
+			row.expense = fmt.Sprintf("%d", record.TravelExpense.Expense)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (this *Timesheet) getTravelTotal() int {
+	total := 0
+	for _, record := range this.WorkTime.Records {
+		if record.TravelExpense != nil {
+			total += record.TravelExpense.Expense
+		}
+	}
+	return total
+}
+
+// WritePDF renders the timesheet as a PDF of a page.
+func (this *Timesheet) WritePDF(w io.Writer, fontPath string) error {
+	doc, err := newDocument(fontPath)
+	if err != nil {
+		return err
+	}
+	pdf := doc.pdf
+	monthly := this.WorkTime
+
+	doc.setFont(16)
+	doc.cell(0, 10, "Timesheet", "", "C")
+	pdf.Ln(12)
+
+	doc.setFont(10)
+	for _, field := range [][2]string{
+		{"Client", this.Client},
+		{"Worker", this.Worker},
+		{"Project", this.ProjectName},
+		{"Month", fmt.Sprintf("%04d-%02d", monthly.Year, monthly.Month)},
+	} {
+		doc.cell(20, 6, field[0]+":", "", "L")
+		doc.cell(0, 6, field[1], "", "L")
+		pdf.Ln(6)
+	}
+	pdf.Ln(4)
+
+	periodCount := this.getPeriodCount()
+	const dateWidth, dayWidth, hoursWidth, expenseWidth, minNoteWidth = 12.0, 10.0, 14.0, 18.0, 24.0
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	// Periods are narrowed if there are so many that the travel note does not fit
+	periodWidth := 20.0
+	available := pageWidth - left - right - dateWidth - dayWidth - hoursWidth - expenseWidth
+	if periodCount > 0 && available-periodWidth*float64(periodCount) < minNoteWidth {
+		periodWidth = (available - minNoteWidth) / float64(periodCount)
+	}
+	noteWidth := available - periodWidth*float64(periodCount)
+
+	const rowHeight = 5.0
+	doc.setFont(8)
+	pdf.SetFillColor(230, 230, 230)
+	header := func(w float64, text string) {
+		pdf.CellFormat(w, rowHeight, doc.translate(text), "1", 0, "C", true, 0, "")
+	}
+	header(dateWidth, "Date")
+	header(dayWidth, "Day")
+	for i := 0; i < periodCount; i++ {
+		header(periodWidth, fmt.Sprintf("Period %d", i+1))
+	}
+	header(hoursWidth, "Hours")
+	header(noteWidth, "Travel")
+	header(expenseWidth, "Expense")
+	pdf.Ln(rowHeight)
+
+	for _, r := range this.getRows(doc) {
+		pdf.SetFillColor(245, 245, 245)
+		row := func(w float64, text, align string) {
+			pdf.CellFormat(w, rowHeight, doc.translate(text), "1", 0, align, r.holiday, 0, "")
+		}
+		row(dateWidth, r.date, "C")
+		row(dayWidth, r.weekday, "C")
+		for _, p := range r.periods {
+			row(periodWidth, p, "C")
+		}
+		row(hoursWidth, r.hours, "R")
+		row(noteWidth, r.note, "L")
+		row(expenseWidth, r.expense, "R")
+		pdf.Ln(rowHeight)
+	}
+
+	doc.setFont(9)
+	labelWidth := dateWidth + dayWidth + periodWidth*float64(periodCount)
+	doc.cell(labelWidth, rowHeight+1, "Total", "1", "R")
+	doc.cell(hoursWidth, rowHeight+1, formatDuration(monthly.GetDuration()), "1", "R")
+	doc.cell(noteWidth, rowHeight+1, "Travel expenses", "1", "R")
+	doc.cell(expenseWidth, rowHeight+1, fmt.Sprintf("%d", this.getTravelTotal()), "1", "R")
+	pdf.Ln(rowHeight + 3)
+
+	if d := monthly.GetBreakDeduction(); d > 0 {
+		doc.cell(0, 5, fmt.Sprintf("Breaks deducted: %s", formatDuration(d)), "", "L")
+		pdf.Ln(5)
+	}
+	if this.Calendar != nil {
+		days, hours := this.Calendar.GetExpected(monthly.Year, monthly.Month)
+		doc.cell(0, 5, fmt.Sprintf("Expected: %s in %d working days", formatDuration(hours), days), "", "L")
+		pdf.Ln(5)
+	}
+
+	// Signature area
+	pdf.Ln(6)
+	boxWidth := (pageWidth - left - right - 10) / 2
+	for i, label := range []string{"Worker", "Approved by"} {
+		if i > 0 {
+			pdf.SetX(left + boxWidth + 10)
+		}
+		doc.cell(boxWidth, 5, label, "LTR", "L")
+	}
+	pdf.Ln(5)
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			pdf.SetX(left + boxWidth + 10)
+		}
+		doc.cell(boxWidth, 16, "", "LR", "L")
+	}
+	pdf.Ln(16)
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			pdf.SetX(left + boxWidth + 10)
+		}
+		doc.cell(boxWidth, 6, "Date:", "LBR", "L")
+	}
+	pdf.Ln(6)
+
+	return doc.write(w)
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"work-time-logging/worktime"
+)
+
+// newTestTimesheet returns a timesheet of 2026-10 where the 5th has four
+// periods, the last of which is overnight, and the 12th is Sports Day.
+func newTestTimesheet(t *testing.T) *Timesheet {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+	monthly := &worktime.MonthlyWorkTime{Year: 2026, Month: 10}
+	for day := 1; day <= 31; day++ {
+		record := worktime.WorkTimeRecord{Date: &worktime.Date{Year: 2026, Month: 10, Day: day}, Periods: make([]worktime.Period, 3)}
+		switch day {
+		case 5:
+			record.Periods = []worktime.Period{
+				{Start: at(5, 9, 0), End: at(5, 10, 0)},
+				{Start: at(5, 11, 0), End: at(5, 12, 0)},
+				{Start: at(5, 13, 0), End: at(5, 14, 30)},
+				{Start: at(5, 23, 0), End: at(6, 1, 0)},
+			}
+			record.TravelExpense = &worktime.TravelExpense{Expense: 500, Note: "Tokyo - Shinagawa"}
+		case 12:
+			record.Periods[0] = worktime.Period{Start: at(12, 10, 0), End: at(12, 12, 0)}
+		}
+		monthly.Records = append(monthly.Records, record)
+	}
+	calendar, err := worktime.NewCalendar(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Timesheet{ProjectName: "example", Client: "Client", Worker: "Worker", WorkTime: monthly, Calendar: calendar}
+}
+
+func TestTimesheetRows(t *testing.T) {
+	timesheet := newTestTimesheet(t)
+	doc, err := newDocument("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := timesheet.getRows(doc)
+	if len(rows) != 31 {
+		t.Fatalf("rows = %d, want 31", len(rows))
+	}
+	tests := []struct {
+		day  int
+		want timesheetRow
+	}{
+		{5, timesheetRow{"10/5", "Mon", []string{"9:00-10:00", "11:00-12:00", "13:00-14:30", "23:00-25:00"}, "5:30", "Tokyo - Shinagawa", "500", false}},
+		{6, timesheetRow{"10/6", "Tue", []string{"", "", "", ""}, "", "", "", false}},
+		{10, timesheetRow{"10/10", "Sat", []string{"", "", "", ""}, "", "", "", true}},
+		{12, timesheetRow{"10/12", "Mon", []string{"10:00-12:00", "", "", ""}, "2:00", "", "", true}},
+	}
+	for _, tt := range tests {
+		if got := rows[tt.day-1]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("row of day %d = %+v, want %+v", tt.day, got, tt.want)
+		}
+	}
+	if got := timesheet.getTravelTotal(); got != 500 {
+		t.Errorf("travel total = %d, want 500", got)
+	}
+}
+
+func TestTimesheetWritePDF(t *testing.T) {
+	var b bytes.Buffer
+	if err := newTestTimesheet(t).WritePDF(&b, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(b.Bytes(), []byte("%PDF-")) || b.Len() < 1000 {
+		t.Errorf("output is not a PDF: %d bytes", b.Len())
+	}
+	if n := bytes.Count(b.Bytes(), []byte("/Type /Page\n")); n != 1 {
+		t.Errorf("pages = %d, want 1", n)
+	}
+}
@@ -69,6 +69,12 @@ func saveToken(path string, token *oauth2.Token) {
 	json.NewEncoder(f).Encode(token)
 }
 
+// HasCredentials reports whether the client secret file is in settingsDir.
+func HasCredentials(settingsDir string) bool {
+	_, err := os.Stat(filepath.Join(settingsDir, "credentials.json"))
+	return err == nil
+}
+
 func GetAPIClient(settingsDir string) *http.Client {
 	b, err := ioutil.ReadFile(filepath.Join(settingsDir, "credentials.json"))
 	if err != nil {
@@ -2,9 +2,12 @@ package spreadsheet
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"golang.org/x/xerrors"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/sheets/v4"
 
 	"work-time-logging/configuration"
@@ -54,3 +57,59 @@ func (this *Spreadsheet) Update(spreadsheetId, sheetName, address string, value
 
 	return nil
 }
+
+type Cell struct {
+	Address string
+	Value   interface{}
+}
+
+// BatchUpdate writes cells of a sheet in a single request, so that either all
+// or none of them are written.
+func (this *Spreadsheet) BatchUpdate(spreadsheetId, sheetName string, cells []Cell) error {
+	srv, err := sheets.New(this.api)
+	if err != nil {
+		return xerrors.Errorf("Unable to retrieve Sheets client: %w", err)
+	}
+
+	req := sheets.BatchUpdateValuesRequest{ValueInputOption: "USER_ENTERED"}
+	for _, cell := range cells {
+		req.Data = append(req.Data, &sheets.ValueRange{
+			Range:  fmt.Sprintf("%s!%s", sheetName, cell.Address),
+			Values: [][]interface{}{[]interface{}{cell.Value}},
+		})
+	}
+	_, err = srv.Spreadsheets.Values.BatchUpdate(spreadsheetId, &req).Do()
+	if err != nil {
+		return xerrors.Errorf("Unable to update sheet: %w", err)
+	}
+
+	return nil
+}
+
+// IsTemporary reports whether err is caused by network failure or
+// an error of the API server which may succeed on retry. Other errors in
+// requests, such as failure of refreshing the token, are not temporary.
+func IsTemporary(err error) bool {
+	var apiErr *googleapi.Error
+	if xerrors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	var opErr *net.OpError
+	if xerrors.As(err, &opErr) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if xerrors.As(err, &dnsErr) {
+		return true
+	}
+	var netErr net.Error
+	return xerrors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsSheetNotFound reports whether err is caused by a range of a sheet which
+// does not exist in the spreadsheet, such as a month not created yet.
+func IsSheetNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return xerrors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest &&
+		strings.Contains(apiErr.Message, "Unable to parse range")
+}
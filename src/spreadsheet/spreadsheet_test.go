@@ -0,0 +1,57 @@
+package spreadsheet
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/xerrors"
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsTemporary(t *testing.T) {
+	request := func(err error) error {
+		return xerrors.Errorf("Unable to retrieve data from sheet: %w",
+			&url.Error{Op: "Get", URL: "https://sheets.googleapis.com/", Err: err})
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", request(&net.OpError{Op: "dial", Net: "tcp", Err: xerrors.New("connection refused")}), true},
+		{"dns", request(&net.DNSError{Err: "no such host", Name: "sheets.googleapis.com"}), true},
+		{"timeout", request(context.DeadlineExceeded), true},
+		{"token", request(&oauth2.RetrieveError{Response: &http.Response{StatusCode: 400}}), false},
+		{"too many requests", xerrors.Errorf("Unable: %w", &googleapi.Error{Code: 429}), true},
+		{"server", xerrors.Errorf("Unable: %w", &googleapi.Error{Code: 503}), true},
+		{"not found", xerrors.Errorf("Unable: %w", &googleapi.Error{Code: 404}), false},
+		{"other", xerrors.New("Invalid range"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTemporary(tt.err); got != tt.want {
+			t.Errorf("%s: IsTemporary(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIsSheetNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"missing sheet", xerrors.Errorf("Unable: %w", &googleapi.Error{Code: 400, Message: "Unable to parse range: 202609!A4:K40"}), true},
+		{"other bad request", xerrors.Errorf("Unable: %w", &googleapi.Error{Code: 400, Message: "Invalid value"}), false},
+		{"not found", xerrors.Errorf("Unable: %w", &googleapi.Error{Code: 404}), false},
+		{"other", xerrors.New("Unable to parse range"), false},
+	}
+	for _, tt := range tests {
+		if got := IsSheetNotFound(tt.err); got != tt.want {
+			t.Errorf("%s: IsSheetNotFound(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package worktime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// BillingPolicy is how work of a project is invoiced.
+type BillingPolicy struct {
+	HourlyRate float64
+	Currency   string
+	// TaxRate is in percent
+	TaxRate float64
+	// Unit is the unit of billed time
+	Unit time.Duration
+	// Rounding is "down", "up" or "nearest"
+	Rounding      string
+	InvoiceNumber string
+	DueDays       int
+}
+
+// currencyDecimals are the digits after the decimal point of currencies other than 2.
+var currencyDecimals = map[string]int{"JPY": 0, "KRW": 0, "VND": 0}
+
+func NewBillingPolicy(config *configuration.BillingConfig) (*BillingPolicy, error) {
+	policy := &BillingPolicy{
+		HourlyRate:    config.HourlyRate,
+		Currency:      "JPY",
+		TaxRate:       10,
+		Unit:          time.Minute,
+		Rounding:      "down",
+		InvoiceNumber: "{yyyy}{mm}-{seq}",
+		DueDays:       30,
+	}
+	if config.HourlyRate < 0 {
+		return nil, fmt.Errorf("Invalid hourly rate: %v", config.HourlyRate)
+	}
+	if config.Currency != "" {
+		if len(config.Currency) != 3 {
+			return nil, fmt.Errorf("Invalid currency: %s", config.Currency)
+		}
+		policy.Currency = strings.ToUpper(config.Currency)
+	}
+	if config.TaxRate != nil {
+		if *config.TaxRate < 0 || *config.TaxRate > 100 {
+			return nil, fmt.Errorf("Invalid tax rate: %v", *config.TaxRate)
+		}
+		policy.TaxRate = *config.TaxRate
+	}
+	if config.Unit < 0 || config.Unit > 60 {
+		return nil, fmt.Errorf("Invalid unit: %d", config.Unit)
+	}
+	if config.Unit > 0 {
+		policy.Unit = time.Duration(config.Unit) * time.Minute
+	}
+	switch config.Rounding {
+	case "":
+	case "down", "up", "nearest":
+		policy.Rounding = config.Rounding
+	default:
+		return nil, fmt.Errorf("Invalid rounding: %s", config.Rounding)
+	}
+	if config.InvoiceNumber != "" {
+		policy.InvoiceNumber = config.InvoiceNumber
+	}
+	if config.DueDays < 0 {
+		return nil, fmt.Errorf("Invalid due days: %d", config.DueDays)
+	}
+	if config.DueDays > 0 {
+		policy.DueDays = config.DueDays
+	}
+	return policy, nil
+}
+
+func GetBillingPolicy(config *configuration.Config, projectName string) (*BillingPolicy, error) {
+	sheet, err := config.FindSpreadsheet(projectName)
+	if err != nil {
+		return nil, err
+	}
+	if sheet.Billing == nil {
+		return nil, fmt.Errorf("Billing is not configured for %s", projectName)
+	}
+	policy, err := NewBillingPolicy(sheet.Billing)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid billing of %s: %w", projectName, err)
+	}
+	return policy, nil
+}
+
+// GetDecimals returns the digits of the minor unit of the currency.
+func (this *BillingPolicy) GetDecimals() int {
+	if d, ok := currencyDecimals[this.Currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// round rounds x to an integer, ignoring errors of floating point.
+func (this *BillingPolicy) round(x float64) int64 {
+	const epsilon = 1e-6
+	switch this.Rounding {
+	case "up":
+		return int64(math.Ceil(x - epsilon))
+	case "nearest":
+		return int64(math.Floor(x + 0.5 + epsilon))
+	}
+	return int64(math.Floor(x + epsilon))
+}
+
+func (this *BillingPolicy) roundDuration(d time.Duration) time.Duration {
+	return time.Duration(this.round(float64(d)/float64(this.Unit))) * this.Unit
+}
+
+// FormatAmount formats an amount in the minor unit with separators of thousands.
+func (this *BillingPolicy) FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	scale := int64(math.Pow10(this.GetDecimals()))
+	digits := fmt.Sprintf("%d", amount/scale)
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	if scale > 1 {
+		fmt.Fprintf(&b, ".%0*d", this.GetDecimals(), amount%scale)
+	}
+	return sign + b.String()
+}
+
+// Bill is the amounts of a month of a project, in the minor unit of the currency.
+type Bill struct {
+	// Duration is work after deduction of breaks, and BilledDuration is it rounded by the unit
+	Duration       time.Duration
+	BilledDuration time.Duration
+	// UnitPrice is the hourly rate
+	UnitPrice int64
+	Labor     int64
+	// TravelDays is the number of days with travel expenses
+	TravelDays    int
+	TravelExpense int64
+	Subtotal      int64
+	Tax           int64
+	Total         int64
+}
+
+// Calculate returns the bill of the month, where the consumption tax is
+// calculated once on the subtotal of work and travel expenses.
+func (this *BillingPolicy) Calculate(monthly *MonthlyWorkTime) *Bill {
+	scale := math.Pow10(this.GetDecimals())
+	bill := &Bill{Duration: monthly.GetDuration()}
+	bill.BilledDuration = this.roundDuration(bill.Duration)
+	bill.UnitPrice = this.round(this.HourlyRate * scale)
+	bill.Labor = this.round(bill.BilledDuration.Hours() * this.HourlyRate * scale)
+	for _, record := range monthly.Records {
+		if record.TravelExpense != nil && record.TravelExpense.Expense != 0 {
+			bill.TravelDays++
+			bill.TravelExpense += int64(record.TravelExpense.Expense) * int64(scale)
+		}
+	}
+	bill.Subtotal = bill.Labor + bill.TravelExpense
+	bill.Tax = this.round(float64(bill.Subtotal) * this.TaxRate / 100)
+	bill.Total = bill.Subtotal + bill.Tax
+	return bill
+}
+
+// InvoiceRegistry keeps numbers of issued invoices, so that an invoice of a
+// month of a project is reissued with the same number.
+type InvoiceRegistry struct {
+	path string
+}
+
+type invoiceRegistryFile struct {
+	// Sequence is the last sequence number issued
+	Sequence int
+	// Numbers are invoice numbers by project and month such as "example 2026-10"
+	Numbers map[string]string
+}
+
+func NewInvoiceRegistry(path string) *InvoiceRegistry {
+	return &InvoiceRegistry{path: path}
+}
+
+func (this *InvoiceRegistry) load() (*invoiceRegistryFile, error) {
+	file := &invoiceRegistryFile{Numbers: map[string]string{}}
+	b, err := ioutil.ReadFile(this.path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read invoice registry: %w", err)
+	}
+	if err := json.Unmarshal(b, file); err != nil {
+		return nil, xerrors.Errorf("Unable to decode invoice registry: %w", err)
+	}
+	if file.Numbers == nil {
+		file.Numbers = map[string]string{}
+	}
+	return file, nil
+}
+
+// GetNumber returns the number of the invoice of the month of the project,
+// issuing a new one by the policy if there has not been.
+func (this *InvoiceRegistry) GetNumber(projectName string, year, month int, policy *BillingPolicy) (string, error) {
+	file, err := this.load()
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s %04d-%02d", projectName, year, month)
+	if number, ok := file.Numbers[key]; ok {
+		return number, nil
+	}
+
+	file.Sequence++
+	number := strings.NewReplacer(
+		"{yyyy}", fmt.Sprintf("%04d", year),
+		"{mm}", fmt.Sprintf("%02d", month),
+		"{project}", projectName,
+		"{seq}", fmt.Sprintf("%04d", file.Sequence),
+	).Replace(policy.InvoiceNumber)
+	for k, n := range file.Numbers {
+		if n == number {
+			return "", fmt.Errorf("Invoice number %s is already issued for %s", number, k)
+		}
+	}
+	file.Numbers[key] = number
+
+	b, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return "", xerrors.Errorf("Unable to encode invoice registry: %w", err)
+	}
+	if err := ioutil.WriteFile(this.path, b, 0600); err != nil {
+		return "", xerrors.Errorf("Unable to write invoice registry: %w", err)
+	}
+	return number, nil
+}
@@ -0,0 +1,74 @@
+package worktime
+
+import (
+	"testing"
+	"time"
+
+	"work-time-logging/configuration"
+)
+
+func TestBillingPolicyCalculate(t *testing.T) {
+	taxRate := func(rate float64) *float64 {
+		return &rate
+	}
+	tests := []struct {
+		name     string
+		config   configuration.BillingConfig
+		duration [2]string
+		travel   int
+		billed   time.Duration
+		subtotal int64
+		tax      int64
+		total    string
+	}{
+		{"default", configuration.BillingConfig{HourlyRate: 5000},
+			[2]string{"9:00", "17:07"}, 0, 8*time.Hour + 7*time.Minute, 40583, 4058, "44,641"},
+		{"unit down", configuration.BillingConfig{HourlyRate: 5000, Unit: 15},
+			[2]string{"9:00", "17:14"}, 1230, 8 * time.Hour, 41230, 4123, "45,353"},
+		{"unit nearest", configuration.BillingConfig{HourlyRate: 5000, Unit: 15, Rounding: "nearest"},
+			[2]string{"9:00", "17:08"}, 0, 8*time.Hour + 15*time.Minute, 41250, 4125, "45,375"},
+		{"tax up", configuration.BillingConfig{HourlyRate: 3333, Unit: 60, Rounding: "up"},
+			[2]string{"9:00", "10:00"}, 0, time.Hour, 3333, 334, "3,667"},
+		{"tax down", configuration.BillingConfig{HourlyRate: 3333, Unit: 60},
+			[2]string{"9:00", "10:00"}, 0, time.Hour, 3333, 333, "3,666"},
+		{"no tax", configuration.BillingConfig{HourlyRate: 3333, TaxRate: taxRate(0)},
+			[2]string{"9:00", "10:00"}, 0, time.Hour, 3333, 0, "3,333"},
+		{"decimal currency", configuration.BillingConfig{HourlyRate: 12.5, Currency: "usd", TaxRate: taxRate(8)},
+			[2]string{"9:00", "10:30"}, 0, 90 * time.Minute, 1875, 150, "20.25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewBillingPolicy(&tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			record := newTestRecord(t, &Date{Year: 2026, Month: 10, Day: 5}, tt.duration)
+			if tt.travel > 0 {
+				record.TravelExpense = &TravelExpense{Expense: tt.travel}
+			}
+			bill := policy.Calculate(&MonthlyWorkTime{Year: 2026, Month: 10, Records: []WorkTimeRecord{record}})
+			if bill.BilledDuration != tt.billed {
+				t.Errorf("billed = %v, want %v", bill.BilledDuration, tt.billed)
+			}
+			if bill.Subtotal != tt.subtotal || bill.Tax != tt.tax {
+				t.Errorf("subtotal, tax = %d, %d, want %d, %d", bill.Subtotal, bill.Tax, tt.subtotal, tt.tax)
+			}
+			if got := policy.FormatAmount(bill.Total); got != tt.total {
+				t.Errorf("total = %s, want %s", got, tt.total)
+			}
+		})
+	}
+}
+
+func TestNewBillingPolicyInvalid(t *testing.T) {
+	for _, config := range []configuration.BillingConfig{
+		{HourlyRate: -1},
+		{Currency: "YEN!"},
+		{Unit: 61},
+		{Rounding: "half"},
+	} {
+		if _, err := NewBillingPolicy(&config); err == nil {
+			t.Errorf("NewBillingPolicy(%+v) succeeded", config)
+		}
+	}
+}
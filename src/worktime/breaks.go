@@ -0,0 +1,120 @@
+package worktime
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// BreakRule requires Break if work in a day is longer than Over.
+type BreakRule struct {
+	Over  time.Duration
+	Break time.Duration
+}
+
+// BreakPolicy is the breaks required in a day of a project, where no rules
+// means that breaks are not checked.
+type BreakPolicy struct {
+	Rules  []BreakRule
+	Action string
+}
+
+// StatutoryBreakRules are the breaks required by the Labor Standards Act.
+var StatutoryBreakRules = []BreakRule{
+	{Over: 6 * time.Hour, Break: 45 * time.Minute},
+	{Over: 8 * time.Hour, Break: 60 * time.Minute},
+}
+
+func NewBreakPolicy(config *configuration.BreakConfig) (*BreakPolicy, error) {
+	if config == nil {
+		return &BreakPolicy{Action: "warn"}, nil
+	}
+
+	policy := &BreakPolicy{Rules: StatutoryBreakRules, Action: "warn"}
+	if len(config.Rules) > 0 {
+		policy.Rules = nil
+		for _, r := range config.Rules {
+			if r.OverHours <= 0 || r.OverHours > 24 || r.BreakMinutes <= 0 {
+				return nil, fmt.Errorf("Invalid break rule: %d minutes over %d hours", r.BreakMinutes, r.OverHours)
+			}
+			policy.Rules = append(policy.Rules, BreakRule{
+				Over:  time.Duration(r.OverHours) * time.Hour,
+				Break: time.Duration(r.BreakMinutes) * time.Minute,
+			})
+		}
+	}
+	switch config.Action {
+	case "":
+	case "warn", "deduct":
+		policy.Action = config.Action
+	default:
+		return nil, fmt.Errorf("Invalid break action: %s", config.Action)
+	}
+	return policy, nil
+}
+
+func GetBreakPolicy(config *configuration.Config, projectName string) (*BreakPolicy, error) {
+	sheet, err := config.FindSpreadsheet(projectName)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := NewBreakPolicy(sheet.Break)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid break of %s: %w", projectName, err)
+	}
+	return policy, nil
+}
+
+// GetRequired returns the break required for work of the duration.
+func (this *BreakPolicy) GetRequired(work time.Duration) time.Duration {
+	var required time.Duration
+	for _, r := range this.Rules {
+		if work > r.Over && r.Break > required {
+			required = r.Break
+		}
+	}
+	return required
+}
+
+// Apply sets the break shortage of each record, which is also deducted from
+// the duration if the action is "deduct".
+func (this *BreakPolicy) Apply(monthlyWorkTime *MonthlyWorkTime) {
+	for i := range monthlyWorkTime.Records {
+		record := &monthlyWorkTime.Records[i]
+		record.BreakShortage = 0
+		record.BreakDeduction = 0
+		shortage := this.GetRequired(record.getWorkDuration()) - record.GetBreak()
+		if shortage <= 0 {
+			continue
+		}
+		record.BreakShortage = shortage
+		if this.Action == "deduct" {
+			record.BreakDeduction = shortage
+		}
+	}
+}
+
+// GetBreak returns the sum of gaps between ended periods of the day.
+func (this *WorkTimeRecord) GetBreak() time.Duration {
+	var periods []Period
+	for _, p := range this.Periods {
+		if !p.IsEmpty() && !p.IsEndEmpty() {
+			periods = append(periods, p)
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+
+	var sum time.Duration
+	for i := 1; i < len(periods); i++ {
+		if gap := periods[i].Start.Sub(periods[i-1].End); gap > 0 {
+			sum += gap
+		}
+	}
+	return sum
+}
@@ -0,0 +1,114 @@
+package worktime
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// Change is a change written by a command with the totals after it.
+type Change struct {
+	Operation string `json:"operation" yaml:"operation"`
+	Project   string `json:"project" yaml:"project"`
+	Date      string `json:"date" yaml:"date"`
+	// Slot is the 1-based position of the period, or 0 for travel expenses
+	Slot int `json:"slot,omitempty" yaml:"slot,omitempty"`
+	// Cells are the A1 addresses written in the spreadsheet storage
+	Cells []string `json:"cells,omitempty" yaml:"cells,omitempty"`
+	// Queued is true if the change is waiting for sync, where the record is not given
+	Queued bool            `json:"queued" yaml:"queued"`
+	Record *ExportedRecord `json:"record,omitempty" yaml:"record,omitempty"`
+	Totals *ChangeTotals   `json:"totals,omitempty" yaml:"totals,omitempty"`
+}
+
+type ChangeTotals struct {
+	DayMinutes   int `json:"dayMinutes" yaml:"dayMinutes"`
+	MonthMinutes int `json:"monthMinutes" yaml:"monthMinutes"`
+}
+
+// FindSlot returns the date and the 0-based slot of the period of the project
+// which starts or ends at t on the date. An end can be of an overnight period
+// of the previous day. The slot is -1 if there is no such period.
+func (this *WorkTime) FindSlot(projectName string, date *Date, startOrEnd string, t *Time) (*Date, int, error) {
+	dates := []*Date{date}
+	if startOrEnd == "end" {
+		dates = append(dates, date.AddDays(-1))
+	}
+	for _, d := range dates {
+		record, err := this.getRecord(projectName, d)
+		if err != nil && d.Month != date.Month && xerrors.Is(err, ErrMonthNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, -1, err
+		}
+		for i, p := range record.Periods {
+			if (startOrEnd == "start" && isSameTime(p.Start, t)) || (startOrEnd == "end" && isSameTime(p.End, t)) {
+				return d, i, nil
+			}
+		}
+	}
+	return date, -1, nil
+}
+
+// getCellAddresses returns the addresses of the columns ("start", "end" or
+// "travel") of the slot, or nil if the project is not in the spreadsheet storage.
+func (this *WorkTime) getCellAddresses(projectName string, date *Date, slot int, columns []string) ([]string, error) {
+	kind, err := this.config.GetStorage(projectName)
+	if err != nil || kind != "spreadsheet" {
+		return nil, err
+	}
+	layout, err := getLayout(this.config, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetName := getSheetName(date.Year, date.Month)
+	var addresses []string
+	for _, column := range columns {
+		var cols []int
+		switch {
+		case column == "travel" && layout.hasTravelExpense():
+			cols = []int{layout.TravelNoteColumn, layout.TravelExpenseColumn}
+		case column == "start" && slot >= 0 && slot < len(layout.PeriodColumns):
+			cols = []int{layout.PeriodColumns[slot][0]}
+		case column == "end" && slot >= 0 && slot < len(layout.PeriodColumns):
+			cols = []int{layout.PeriodColumns[slot][1]}
+		}
+		for _, col := range cols {
+			addresses = append(addresses, fmt.Sprintf("%s!%s", sheetName, layout.getCellAddress(date.Day-1, col)))
+		}
+	}
+	return addresses, nil
+}
+
+// GetChange returns the change of the columns of the slot, which is 0-based
+// or -1 for travel expenses, with the record of the day and the totals.
+func (this *WorkTime) GetChange(operation, projectName string, date *Date, slot int, columns []string) (*Change, error) {
+	change := &Change{Operation: operation, Project: projectName, Date: date.String(), Slot: slot + 1}
+	cells, err := this.getCellAddresses(projectName, date, slot, columns)
+	if err != nil {
+		return nil, err
+	}
+	change.Cells = cells
+
+	monthlyWorkTime, err := this.Get(projectName, date.Year, date.Month)
+	if err != nil {
+		return nil, err
+	}
+	for i := range monthlyWorkTime.Records {
+		record := &monthlyWorkTime.Records[i]
+		if !record.Date.Equal(date) {
+			continue
+		}
+		change.Record = NewExportedRecord(projectName, record)
+		if change.Record == nil {
+			change.Record = &ExportedRecord{Project: projectName, Date: date.String(), Periods: []ExportedPeriod{}}
+		}
+		change.Totals = &ChangeTotals{
+			DayMinutes:   ToMinutes(record.GetDuration()),
+			MonthMinutes: ToMinutes(monthlyWorkTime.GetDuration()),
+		}
+	}
+	return change, nil
+}
@@ -5,16 +5,85 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
 )
 
+// GetLocation returns the time zone of the project.
+func GetLocation(config *configuration.Config, projectName string) (*time.Location, error) {
+	name, err := config.GetTimeZone(projectName)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid time zone of %s: %w", projectName, err)
+	}
+	return location, nil
+}
+
+// Now returns the current date and time in location.
+func Now(location *time.Location) (*Date, *Time) {
+	now := time.Now().In(location)
+	return &Date{Year: now.Year(), Month: int(now.Month()), Day: now.Day()},
+		&Time{Hour: now.Hour(), Minute: now.Minute()}
+}
+
 type Date struct {
 	Year, Month, Day int
 }
 
+func (this *Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", this.Year, this.Month, this.Day)
+}
+
 func (this *Date) Equal(date *Date) bool {
 	return *this == *date
 }
 
+func (this *Date) Before(date *Date) bool {
+	if this.Year != date.Year {
+		return this.Year < date.Year
+	}
+	if this.Month != date.Month {
+		return this.Month < date.Month
+	}
+	return this.Day < date.Day
+}
+
+// AddDays returns the date n days after, or before if n is negative.
+func (this *Date) AddDays(n int) *Date {
+	d := time.Date(this.Year, time.Month(this.Month), this.Day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, n)
+	return &Date{Year: d.Year(), Month: int(d.Month()), Day: d.Day()}
+}
+
+// ParseDate parses YYYY-MM-DD, "today", "yesterday" or relative days such as "-2d".
+func ParseDate(value string, today *Date) (*Date, error) {
+	switch value {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDays(-1), nil
+	}
+
+	if strings.HasSuffix(value, "d") && (strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+")) {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date: %s", value)
+		}
+		return today.AddDays(n), nil
+	}
+
+	d, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid date: %s", value)
+	}
+	return &Date{Year: d.Year(), Month: int(d.Month()), Day: d.Day()}, nil
+}
+
 func (this *Date) IsLastDayOfMonth() bool {
 	d := time.Date(this.Year, time.Month(this.Month), this.Day, 0, 0, 0, 0, time.UTC)
 	return d.Month() != d.AddDate(0, 0, 1).Month()
@@ -45,13 +114,20 @@ func ParseHHMM(hhmm string) (*Time, error) {
 	return &Time{h, m}, nil
 }
 
-func (this *Time) RoundTime() *Time {
-	diff := (10 - this.Minute%10) % 10
-	m := this.Minute + diff
-	h := this.Hour
-	if m >= 60 {
-		h++
-		m = 0
+// Round rounds the time to a multiple of unit minutes in the direction,
+// which is "up", "down", "nearest" or "none". Times late at night can be
+// rounded to 24:00, which RoundingPolicy keeps in the day or moves to 0:00.
+func (this *Time) Round(unit int, direction string) *Time {
+	m := this.Hour*60 + this.Minute
+	if unit > 1 {
+		switch direction {
+		case "up":
+			m = (m + unit - 1) / unit * unit
+		case "down":
+			m = m / unit * unit
+		case "nearest":
+			m = (m + unit/2) / unit * unit
+		}
 	}
-	return &Time{Hour: h, Minute: m}
+	return &Time{Hour: m / 60, Minute: m % 60}
 }
@@ -0,0 +1,35 @@
+package worktime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodDurationDST(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		date       *Date
+		start, end string
+		want       time.Duration
+	}{
+		{"spring forward", &Date{Year: 2026, Month: 3, Day: 8}, "1:00", "4:00", 2 * time.Hour},
+		{"fall back", &Date{Year: 2026, Month: 11, Day: 1}, "0:00", "3:00", 4 * time.Hour},
+		{"overnight before fall back", &Date{Year: 2026, Month: 10, Day: 31}, "22:00", "6:00", 9 * time.Hour},
+		{"standard time", &Date{Year: 2026, Month: 11, Day: 2}, "9:00", "17:00", 8 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parsePeriod(tt.date, tt.start, tt.end, location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.GetDuration(); got != tt.want {
+				t.Errorf("duration = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
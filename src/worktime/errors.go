@@ -0,0 +1,41 @@
+package worktime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors of mutations, which are returned wrapped with the project and the date.
+var (
+	ErrNotFound       = errors.New("specified date not found")
+	ErrAlreadyStarted = errors.New("already started")
+	ErrNotStarted     = errors.New("not started")
+	ErrNoFreeSlot     = errors.New("empty period not found")
+	ErrOverlap        = errors.New("period overlaps another")
+	ErrTooLong        = errors.New("period is too long")
+)
+
+// ErrMonthNotFound is returned by storages for a month which has no sheet.
+var ErrMonthNotFound = errors.New("month not found")
+
+// APIError is a failure of a storage to read or write cells.
+type APIError struct {
+	Operation   string // "read" or "write"
+	ProjectName string
+	Date        *Date
+	Address     string
+	Err         error
+}
+
+func (this *APIError) Error() string {
+	if this.Date == nil {
+		return fmt.Sprintf("Unable to %s %s of %s: %v",
+			this.Operation, this.Address, this.ProjectName, this.Err)
+	}
+	return fmt.Sprintf("Unable to %s %s of %s on %v: %v",
+		this.Operation, this.Address, this.ProjectName, this.Date, this.Err)
+}
+
+func (this *APIError) Unwrap() error {
+	return this.Err
+}
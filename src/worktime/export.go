@@ -0,0 +1,173 @@
+package worktime
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Export is work time records of projects in a date range, which is
+// serialized in the following schemas. Days without any period or travel
+// expense are omitted. Timestamps are in ISO 8601 with the offset of the time
+// zone of the project, and durations are in minutes.
+//
+// JSON:
+//
+//	{
+//	  "version": 1,
+//	  "from": "2026-10-01",  // inclusive
+//	  "to": "2026-10-31",    // inclusive
+//	  "records": [{
+//	    "project": "example",
+//	    "date": "2026-10-01",
+//	    "minutes": 480,                // work after deduction of breaks
+//	    "breakDeductionMinutes": 0,
+//	    "periods": [{
+//	      "slot": 1,                   // 1-based position in the day
+//	      "start": "2026-10-01T09:00:00+09:00",
+//	      "end": "2026-10-01T18:00:00+09:00",  // null if not ended
+//	      "minutes": 540
+//	    }],
+//	    "travelExpense": {"expense": 500, "note": "Tokyo - Shinagawa"}  // omitted if none
+//	  }]
+//	}
+//
+// CSV has a header and rows of a type, where columns not for the type are empty:
+//
+//	project,date,type,slot,start,end,minutes,expense,note
+//	example,2026-10-01,period,1,2026-10-01T09:00:00+09:00,2026-10-01T18:00:00+09:00,540,,
+//	example,2026-10-01,deduction,,,,60,,
+//	example,2026-10-01,travel,,,,,500,Tokyo - Shinagawa
+type Export struct {
+	Version int              `json:"version" yaml:"version"`
+	From    string           `json:"from" yaml:"from"`
+	To      string           `json:"to" yaml:"to"`
+	Records []ExportedRecord `json:"records" yaml:"records"`
+}
+
+const ExportVersion = 1
+
+type ExportedRecord struct {
+	Project               string                 `json:"project" yaml:"project"`
+	Date                  string                 `json:"date" yaml:"date"`
+	Minutes               int                    `json:"minutes" yaml:"minutes"`
+	BreakDeductionMinutes int                    `json:"breakDeductionMinutes" yaml:"breakDeductionMinutes"`
+	Periods               []ExportedPeriod       `json:"periods" yaml:"periods"`
+	TravelExpense         *ExportedTravelExpense `json:"travelExpense,omitempty" yaml:"travelExpense,omitempty"`
+}
+
+type ExportedPeriod struct {
+	Slot    int     `json:"slot" yaml:"slot"`
+	Start   string  `json:"start" yaml:"start"`
+	End     *string `json:"end" yaml:"end"`
+	Minutes int     `json:"minutes" yaml:"minutes"`
+}
+
+type ExportedTravelExpense struct {
+	Expense int    `json:"expense" yaml:"expense"`
+	Note    string `json:"note" yaml:"note"`
+}
+
+var exportCSVHeader = []string{"project", "date", "type", "slot", "start", "end", "minutes", "expense", "note"}
+
+// ToMinutes returns the duration in whole minutes.
+func ToMinutes(d time.Duration) int {
+	return int(d / time.Minute)
+}
+
+// NewExportedRecord returns nil if the record has neither period nor travel expense.
+func NewExportedRecord(projectName string, record *WorkTimeRecord) *ExportedRecord {
+	exported := &ExportedRecord{
+		Project:               projectName,
+		Date:                  record.Date.String(),
+		Minutes:               ToMinutes(record.GetDuration()),
+		BreakDeductionMinutes: ToMinutes(record.BreakDeduction),
+		Periods:               []ExportedPeriod{},
+	}
+	for i, p := range record.Periods {
+		if p.IsEmpty() {
+			continue
+		}
+		period := ExportedPeriod{Slot: i + 1, Start: p.Start.Format(time.RFC3339), Minutes: ToMinutes(p.GetDuration())}
+		if !p.IsEndEmpty() {
+			end := p.End.Format(time.RFC3339)
+			period.End = &end
+		}
+		exported.Periods = append(exported.Periods, period)
+	}
+	if record.TravelExpense != nil {
+		exported.TravelExpense = &ExportedTravelExpense{Expense: record.TravelExpense.Expense, Note: record.TravelExpense.Note}
+	}
+	if len(exported.Periods) == 0 && exported.TravelExpense == nil {
+		return nil
+	}
+	return exported
+}
+
+// Export returns records of the projects from one date to another inclusive,
+// sorted by project in the given order and then by date, with issues of rows
+// which are not exported as they cannot be parsed.
+func (this *WorkTime) Export(projectNames []string, from, to *Date) (*Export, []Issue, error) {
+	if to.Before(from) {
+		return nil, nil, fmt.Errorf("Invalid range: %v - %v", from, to)
+	}
+	export := &Export{Version: ExportVersion, From: from.String(), To: to.String(), Records: []ExportedRecord{}}
+	var issues []Issue
+	for _, projectName := range projectNames {
+		if _, err := this.config.FindSpreadsheet(projectName); err != nil {
+			return nil, nil, err
+		}
+		records, diagnostics, err := this.getRecords(projectName, from, to)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, d := range diagnostics {
+			issues = append(issues, Issue{Kind: IssueParse, ProjectName: projectName, Slot: -1, Message: d.String()})
+		}
+		for i := range records {
+			if exported := NewExportedRecord(projectName, &records[i]); exported != nil {
+				export.Records = append(export.Records, *exported)
+			}
+		}
+	}
+	return export, issues, nil
+}
+
+func (this *Export) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(this); err != nil {
+		return xerrors.Errorf("Unable to write JSON: %w", err)
+	}
+	return nil
+}
+
+func (this *Export) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write(exportCSVHeader)
+	for _, r := range this.Records {
+		for _, p := range r.Periods {
+			end := ""
+			if p.End != nil {
+				end = *p.End
+			}
+			writer.Write([]string{r.Project, r.Date, "period", strconv.Itoa(p.Slot), p.Start, end, strconv.Itoa(p.Minutes), "", ""})
+		}
+		if r.BreakDeductionMinutes > 0 {
+			writer.Write([]string{r.Project, r.Date, "deduction", "", "", "", strconv.Itoa(r.BreakDeductionMinutes), "", ""})
+		}
+		if t := r.TravelExpense; t != nil {
+			writer.Write([]string{r.Project, r.Date, "travel", "", "", "", "", strconv.Itoa(t.Expense), t.Note})
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return xerrors.Errorf("Unable to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package worktime
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"work-time-logging/configuration"
+)
+
+func newTestExport(t *testing.T) *Export {
+	w := newTestWorkTime("a")
+	w.config.Spreadsheets[0].Break = &configuration.BreakConfig{Action: "deduct"}
+	date := &Date{Year: 2026, Month: 10, Day: 1}
+	changes := []PeriodChange{{date, 0, hm(9, 0), hm(18, 0)}, {date, 1, hm(19, 0), nil}}
+	if err := w.storage.ReplacePeriods("a", changes); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetTravelExpense("a", date, 500, "Tokyo - Shinagawa"); err != nil {
+		t.Fatal(err)
+	}
+	export, issues, err := w.Export([]string{"a"}, date, date.AddDays(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) > 0 {
+		t.Fatalf("issues = %v", issues)
+	}
+	return export
+}
+
+func TestExportCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := newTestExport(t).WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"project,date,type,slot,start,end,minutes,expense,note",
+		"a,2026-10-01,period,1,2026-10-01T09:00:00+09:00,2026-10-01T18:00:00+09:00,540,,",
+		"a,2026-10-01,period,2,2026-10-01T19:00:00+09:00,,0,,",
+		"a,2026-10-01,deduction,,,,60,,",
+		"a,2026-10-01,travel,,,,,500,Tokyo - Shinagawa",
+	}, "\n") + "\n"
+	if got := b.String(); got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := newTestExport(t).WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"version": 1.0,
+		"from":    "2026-10-01",
+		"to":      "2026-10-02",
+		"records": []interface{}{map[string]interface{}{
+			"project":               "a",
+			"date":                  "2026-10-01",
+			"minutes":               480.0,
+			"breakDeductionMinutes": 60.0,
+			"periods": []interface{}{
+				map[string]interface{}{"slot": 1.0, "start": "2026-10-01T09:00:00+09:00", "end": "2026-10-01T18:00:00+09:00", "minutes": 540.0},
+				map[string]interface{}{"slot": 2.0, "start": "2026-10-01T19:00:00+09:00", "end": nil, "minutes": 0.0},
+			},
+			"travelExpense": map[string]interface{}{"expense": 500.0, "note": "Tokyo - Shinagawa"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON = %s", b.String())
+	}
+}
+
+func TestExportIssues(t *testing.T) {
+	w := newTestWorkTime("a")
+	storage := w.storage.(*MemoryStorage)
+	rows := storage.getRows("a", 2026, 10)
+	rows[4][DefaultLayout.PeriodColumns[0][0]] = "99:00"
+	rows[4][DefaultLayout.PeriodColumns[0][1]] = "18:00"
+	export, issues, err := w.Export([]string{"a"}, &Date{Year: 2026, Month: 10, Day: 1}, &Date{Year: 2026, Month: 10, Day: 31})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Records) != 0 {
+		t.Errorf("records = %v", export.Records)
+	}
+	if len(issues) != 1 || issues[0].Kind != IssueParse || issues[0].ProjectName != "a" {
+		t.Errorf("issues = %v", issues)
+	}
+}
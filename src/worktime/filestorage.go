@@ -0,0 +1,168 @@
+package worktime
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// FileStorage stores work time records in local CSV files.
+// Each file holds one month of a project in the same layout as A4:K40 of the sheet,
+// which is extended with pairs of period columns when a day needs more periods.
+type FileStorage struct {
+	dir string
+}
+
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{dir: dir}
+}
+
+func (this *FileStorage) getFilePath(projectName string, year, month int) (string, error) {
+	if projectName == "" || strings.ContainsAny(projectName, `/\`) || projectName == "." || projectName == ".." {
+		return "", fmt.Errorf("Invalid project name: %s", projectName)
+	}
+	return filepath.Join(this.dir, projectName, getSheetName(year, month)+".csv"), nil
+}
+
+func (this *FileStorage) readRows(projectName string, year, month int) ([][]string, error) {
+	path, err := this.getFilePath(projectName, year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return newSheetRows(year, month), nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open file: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read file: %s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, xerrors.Errorf("Empty file: %s", path)
+	}
+	width := DefaultLayout.lastColumn() + 1
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+func (this *FileStorage) writeRows(projectName string, year, month int, rows [][]string) error {
+	path, err := this.getFilePath(projectName, year, month)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return xerrors.Errorf("Unable to create directory: %w", err)
+	}
+
+	// Write to a temporary file first not to leave a broken file
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-*.csv")
+	if err != nil {
+		return xerrors.Errorf("Unable to create file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	w := csv.NewWriter(f)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		f.Close()
+		return xerrors.Errorf("Unable to write file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return xerrors.Errorf("Unable to write file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return xerrors.Errorf("Unable to write file: %w", err)
+	}
+	return nil
+}
+
+func (this *FileStorage) Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error) {
+	rows, err := this.readRows(projectName, year, month)
+	if err != nil {
+		return nil, err
+	}
+	return parseSheetRows(year, month, rows, location)
+}
+
+func (this *FileStorage) UpdatePeriod(projectName string, date *Date, periodIndex int, startOrEnd string, time *Time) error {
+	return this.setPeriod(projectName, date, periodIndex, map[string]*Time{startOrEnd: time})
+}
+
+func (this *FileStorage) ReplacePeriods(projectName string, changes []PeriodChange) error {
+	year, month, err := getMonth(changes)
+	if err != nil {
+		return err
+	}
+	rows, err := this.readRows(projectName, year, month)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		rows, err = setSheetPeriod(year, month, rows, c.Date, c.PeriodIndex, map[string]*Time{"start": c.Start, "end": c.End})
+		if err != nil {
+			return err
+		}
+	}
+	if err := this.writeRows(projectName, year, month, rows); err != nil {
+		path, _ := this.getFilePath(projectName, year, month)
+		return &APIError{Operation: "write", ProjectName: projectName, Date: changes[0].Date, Address: path, Err: err}
+	}
+	return nil
+}
+
+func (this *FileStorage) setPeriod(projectName string, date *Date, periodIndex int, times map[string]*Time) error {
+	rows, err := this.readRows(projectName, date.Year, date.Month)
+	if err != nil {
+		return err
+	}
+	rows, err = setSheetPeriod(date.Year, date.Month, rows, date, periodIndex, times)
+	if err != nil {
+		return err
+	}
+	if err := this.writeRows(projectName, date.Year, date.Month, rows); err != nil {
+		path, _ := this.getFilePath(projectName, date.Year, date.Month)
+		return &APIError{Operation: "write", ProjectName: projectName, Date: date, Address: path, Err: err}
+	}
+	return nil
+}
+
+func (this *FileStorage) UpdateTravelExpense(projectName string, date *Date, expense int, note string) error {
+	rows, err := this.readRows(projectName, date.Year, date.Month)
+	if err != nil {
+		return err
+	}
+	if err := setSheetTravelExpense(rows, date, expense, note); err != nil {
+		return err
+	}
+	if err := this.writeRows(projectName, date.Year, date.Month, rows); err != nil {
+		path, _ := this.getFilePath(projectName, date.Year, date.Month)
+		return &APIError{Operation: "write", ProjectName: projectName, Date: date, Address: path, Err: err}
+	}
+	return nil
+}
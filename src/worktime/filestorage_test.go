@@ -0,0 +1,101 @@
+package worktime
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStorageRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		periods   [][2]*Time
+		wantWidth int
+	}{
+		{"one period", [][2]*Time{{hm(9, 0), hm(12, 0)}}, 11},
+		{"overnight", [][2]*Time{{hm(22, 0), hm(2, 0)}}, 11},
+		{"open", [][2]*Time{{hm(9, 0), nil}}, 11},
+		{"extended", [][2]*Time{{hm(9, 0), hm(10, 0)}, {hm(11, 0), hm(12, 0)}, {hm(13, 0), hm(14, 0)}, {hm(15, 0), hm(16, 30)}}, 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			date := &Date{Year: 2026, Month: 10, Day: 5}
+			storage := NewFileStorage(dir)
+			for i, p := range tt.periods {
+				if err := storage.ReplacePeriods("a", []PeriodChange{{date, i, p[0], p[1]}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := storage.UpdateTravelExpense("a", date, 500, "Tokyo - Shinagawa"); err != nil {
+				t.Fatal(err)
+			}
+
+			// Read back by another instance
+			monthly, err := NewFileStorage(dir).Get("a", 2026, 10, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(monthly.Diagnostics) > 0 {
+				t.Fatalf("diagnostics = %v", monthly.Diagnostics)
+			}
+			if len(monthly.Records) != 31 {
+				t.Fatalf("records = %d, want 31", len(monthly.Records))
+			}
+			record := monthly.Records[date.Day-1]
+			var want time.Duration
+			for i, p := range tt.periods {
+				got := record.Periods[i]
+				if !isSameTime(got.Start, p[0]) || (p[1] == nil) != got.IsEndEmpty() || (p[1] != nil && !isSameTime(got.End, p[1])) {
+					t.Errorf("period %d = %s, want %s-%s", i+1, formatPeriod(&got), formatTime(p[0]), formatTime(p[1]))
+				}
+				want += got.GetDuration()
+			}
+			if got := monthly.GetDuration(); got != want {
+				t.Errorf("duration = %v, want %v", got, want)
+			}
+			if te := record.TravelExpense; te == nil || te.Expense != 500 || te.Note != "Tokyo - Shinagawa" {
+				t.Errorf("travel expense = %+v", te)
+			}
+
+			f, err := os.Open(filepath.Join(dir, "a", "202610.csv"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			rows, err := csv.NewReader(f).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 32 {
+				t.Errorf("rows = %d, want 32 with the total", len(rows))
+			}
+			for i, row := range rows {
+				if len(row) != tt.wantWidth {
+					t.Errorf("row %d has %d columns, want %d", i+1, len(row), tt.wantWidth)
+				}
+			}
+		})
+	}
+}
+
+func TestFileStorageMissingMonth(t *testing.T) {
+	monthly, err := NewFileStorage(t.TempDir()).Get("a", 2026, 2, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monthly.Records) != 28 || len(monthly.Diagnostics) > 0 || monthly.GetDuration() != 0 {
+		t.Errorf("records = %d, diagnostics = %v", len(monthly.Records), monthly.Diagnostics)
+	}
+}
+
+func TestFileStorageInvalidProjectName(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+	for _, name := range []string{"", ".", "..", "a/b", `a\b`} {
+		if _, err := storage.Get(name, 2026, 10, time.UTC); err == nil {
+			t.Errorf("Get of %q succeeded", name)
+		}
+	}
+}
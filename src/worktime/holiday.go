@@ -0,0 +1,215 @@
+package worktime
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// Japanese national holidays are computed by the rules of the Act on National
+// Holidays in force since 2007, with the changes for the years 2019 to 2021.
+// The equinoxes are approximated by the formula valid from 1980 to 2099.
+
+func nthWeekday(year, month, n int, weekday time.Weekday) int {
+	first := (&Date{Year: year, Month: month, Day: 1}).GetWeekday()
+	return 1 + (int(weekday)-int(first)+7)%7 + 7*(n-1)
+}
+
+func vernalEquinox(year int) int {
+	return int(20.8431+0.242194*float64(year-1980)) - (year-1980)/4
+}
+
+func autumnalEquinox(year int) int {
+	return int(23.2488+0.242194*float64(year-1980)) - (year-1980)/4
+}
+
+// getStatutoryHoliday returns the holiday of the date given by the calendar,
+// not including substitute holidays and days between holidays.
+func getStatutoryHoliday(date *Date) (string, bool) {
+	y, m, d := date.Year, date.Month, date.Day
+	monday := func(n int) bool {
+		return d == nthWeekday(y, m, n, time.Monday)
+	}
+
+	switch {
+	case m == 1 && d == 1:
+		return "元日", true
+	case m == 1 && monday(2):
+		return "成人の日", true
+	case m == 2 && d == 11:
+		return "建国記念の日", true
+	case m == 2 && d == 23 && y >= 2020:
+		return "天皇誕生日", true
+	case m == 3 && d == vernalEquinox(y):
+		return "春分の日", true
+	case m == 4 && d == 29:
+		return "昭和の日", true
+	case m == 5 && d == 3:
+		return "憲法記念日", true
+	case m == 5 && d == 4:
+		return "みどりの日", true
+	case m == 5 && d == 5:
+		return "こどもの日", true
+	case y == 2019 && m == 5 && d == 1:
+		return "天皇の即位の日", true
+	case y == 2020 && m == 7 && d == 23, y == 2021 && m == 7 && d == 22,
+		y != 2020 && y != 2021 && m == 7 && monday(3):
+		return "海の日", true
+	case y == 2020 && m == 7 && d == 24, y == 2021 && m == 7 && d == 23:
+		return "スポーツの日", true
+	case y == 2020 && m == 8 && d == 10, y == 2021 && m == 8 && d == 8,
+		y != 2020 && y != 2021 && y >= 2016 && m == 8 && d == 11:
+		return "山の日", true
+	case m == 9 && monday(3):
+		return "敬老の日", true
+	case m == 9 && d == autumnalEquinox(y):
+		return "秋分の日", true
+	case y != 2020 && y != 2021 && m == 10 && monday(2):
+		if y >= 2020 {
+			return "スポーツの日", true
+		}
+		return "体育の日", true
+	case y == 2019 && m == 10 && d == 22:
+		return "即位礼正殿の儀の行われる日", true
+	case m == 11 && d == 3:
+		return "文化の日", true
+	case m == 11 && d == 23:
+		return "勤労感謝の日", true
+	case m == 12 && d == 23 && y < 2019:
+		return "天皇誕生日", true
+	}
+	return "", false
+}
+
+// GetNationalHoliday returns the name of the Japanese national holiday of the
+// date, including substitute holidays and days between two holidays.
+func GetNationalHoliday(date *Date) (string, bool) {
+	if name, ok := getStatutoryHoliday(date); ok {
+		return name, true
+	}
+
+	// A holiday on Sunday is substituted by the next day which is not a holiday
+	for d := date.AddDays(-1); ; d = d.AddDays(-1) {
+		if _, ok := getStatutoryHoliday(d); !ok {
+			break
+		}
+		if d.GetWeekday() == time.Sunday {
+			return "振替休日", true
+		}
+	}
+
+	_, before := getStatutoryHoliday(date.AddDays(-1))
+	_, after := getStatutoryHoliday(date.AddDays(1))
+	if before && after && date.GetWeekday() != time.Sunday {
+		return "国民の休日", true
+	}
+	return "", false
+}
+
+// Calendar is the working days of a project.
+type Calendar struct {
+	DaysOff          []time.Weekday
+	NationalHolidays bool
+	// Holidays are company holidays by date
+	Holidays   map[Date]string
+	DailyHours time.Duration
+}
+
+// GetHoliday returns the name of the company or national holiday of the date.
+func (this *Calendar) GetHoliday(date *Date) (string, bool) {
+	if name, ok := this.Holidays[*date]; ok {
+		return name, true
+	}
+	if this.NationalHolidays {
+		return GetNationalHoliday(date)
+	}
+	return "", false
+}
+
+func (this *Calendar) IsWorkingDay(date *Date) bool {
+	for _, w := range this.DaysOff {
+		if date.GetWeekday() == w {
+			return false
+		}
+	}
+	_, holiday := this.GetHoliday(date)
+	return !holiday
+}
+
+// GetExpected returns the number of working days of the month and the hours
+// expected to work in them.
+func (this *Calendar) GetExpected(year, month int) (int, time.Duration) {
+	days := 0
+	for d := (&Date{Year: year, Month: month, Day: 1}); d.Month == month; d = d.AddDays(1) {
+		if this.IsWorkingDay(d) {
+			days++
+		}
+	}
+	return days, time.Duration(days) * this.DailyHours
+}
+
+func parseHolidays(holidays map[string]string, calendar *Calendar) error {
+	for value, name := range holidays {
+		d, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return fmt.Errorf("Invalid holiday: %s", value)
+		}
+		calendar.Holidays[Date{Year: d.Year(), Month: int(d.Month()), Day: d.Day()}] = name
+	}
+	return nil
+}
+
+// NewCalendar returns the calendar of the config with company holidays of all projects.
+func NewCalendar(config *configuration.CalendarConfig, holidays map[string]string) (*Calendar, error) {
+	calendar := &Calendar{
+		DaysOff:          []time.Weekday{time.Saturday, time.Sunday},
+		NationalHolidays: true,
+		Holidays:         map[Date]string{},
+		DailyHours:       8 * time.Hour,
+	}
+	if err := parseHolidays(holidays, calendar); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return calendar, nil
+	}
+
+	if config.DaysOff != nil {
+		calendar.DaysOff = nil
+		for _, name := range config.DaysOff {
+			w, ok := weekdayNames[name]
+			if !ok {
+				return nil, fmt.Errorf("Invalid weekday: %s", name)
+			}
+			calendar.DaysOff = append(calendar.DaysOff, w)
+		}
+	}
+	if config.NationalHolidays != nil {
+		calendar.NationalHolidays = *config.NationalHolidays
+	}
+	if err := parseHolidays(config.Holidays, calendar); err != nil {
+		return nil, err
+	}
+	if config.DailyHours < 0 || config.DailyHours > 24 {
+		return nil, fmt.Errorf("Invalid daily hours: %d", config.DailyHours)
+	}
+	if config.DailyHours > 0 {
+		calendar.DailyHours = time.Duration(config.DailyHours) * time.Hour
+	}
+	return calendar, nil
+}
+
+func GetCalendar(config *configuration.Config, projectName string) (*Calendar, error) {
+	sheet, err := config.FindSpreadsheet(projectName)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := NewCalendar(sheet.Calendar, config.Holidays)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid calendar of %s: %w", projectName, err)
+	}
+	return calendar, nil
+}
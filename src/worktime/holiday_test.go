@@ -0,0 +1,40 @@
+package worktime
+
+import "testing"
+
+func TestGetNationalHoliday(t *testing.T) {
+	tests := []struct {
+		date *Date
+		want string
+	}{
+		{&Date{Year: 2019, Month: 4, Day: 30}, "国民の休日"},
+		{&Date{Year: 2019, Month: 5, Day: 1}, "天皇の即位の日"},
+		{&Date{Year: 2019, Month: 5, Day: 6}, "振替休日"},
+		{&Date{Year: 2019, Month: 10, Day: 14}, "体育の日"},
+		{&Date{Year: 2019, Month: 10, Day: 22}, "即位礼正殿の儀の行われる日"},
+		{&Date{Year: 2019, Month: 12, Day: 23}, ""},
+		{&Date{Year: 2020, Month: 2, Day: 24}, "振替休日"},
+		{&Date{Year: 2020, Month: 7, Day: 20}, ""},
+		{&Date{Year: 2020, Month: 7, Day: 23}, "海の日"},
+		{&Date{Year: 2020, Month: 7, Day: 24}, "スポーツの日"},
+		{&Date{Year: 2020, Month: 8, Day: 10}, "山の日"},
+		{&Date{Year: 2020, Month: 10, Day: 12}, ""},
+		{&Date{Year: 2021, Month: 7, Day: 22}, "海の日"},
+		{&Date{Year: 2021, Month: 7, Day: 23}, "スポーツの日"},
+		{&Date{Year: 2021, Month: 8, Day: 9}, "振替休日"},
+		{&Date{Year: 2021, Month: 8, Day: 11}, ""},
+		{&Date{Year: 2023, Month: 1, Day: 2}, "振替休日"},
+		{&Date{Year: 2026, Month: 3, Day: 20}, "春分の日"},
+		{&Date{Year: 2026, Month: 9, Day: 21}, "敬老の日"},
+		{&Date{Year: 2026, Month: 9, Day: 22}, "国民の休日"},
+		{&Date{Year: 2026, Month: 9, Day: 23}, "秋分の日"},
+		{&Date{Year: 2026, Month: 10, Day: 12}, "スポーツの日"},
+		{&Date{Year: 2026, Month: 10, Day: 13}, ""},
+	}
+	for _, tt := range tests {
+		got, ok := GetNationalHoliday(tt.date)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("GetNationalHoliday(%v) = %q, %v, want %q", tt.date, got, ok, tt.want)
+		}
+	}
+}
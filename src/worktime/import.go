@@ -0,0 +1,467 @@
+package worktime
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// ImportEntry is a period or a travel expense of a day to be imported.
+type ImportEntry struct {
+	ProjectName   string
+	Date          *Date
+	Start, End    *Time
+	TravelExpense *TravelExpense
+}
+
+func (this *ImportEntry) String() string {
+	if this.TravelExpense != nil {
+		return fmt.Sprintf("%s %v travel %d %s", this.ProjectName, this.Date, this.TravelExpense.Expense, this.TravelExpense.Note)
+	}
+	return fmt.Sprintf("%s %v %s-%s", this.ProjectName, this.Date, formatTime(this.Start), formatTime(this.End))
+}
+
+// CSVMapping is the names of columns in the header of CSV to be imported.
+// Start and End are times such as "9:00" or "09:00:00" on Date, or
+// timestamps such as "2026-10-01 09:00" or in ISO 8601, when Date can be
+// omitted. Project can be omitted if the project is given to ReadImportCSV.
+type CSVMapping struct {
+	Project, Date, Start, End string
+}
+
+// DefaultCSVMapping reads CSV written by Export.
+var DefaultCSVMapping = &CSVMapping{Project: "project", Date: "date", Start: "start", End: "end"}
+
+// ParseCSVMapping parses a mapping such as "date=Start date,start=Start time",
+// where fields not given take the default.
+func ParseCSVMapping(value string) (*CSVMapping, error) {
+	mapping := *DefaultCSVMapping
+	if value == "" {
+		return &mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid mapping: %s", pair)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "project":
+			mapping.Project = kv[1]
+		case "date":
+			mapping.Date = kv[1]
+		case "start":
+			mapping.Start = kv[1]
+		case "end":
+			mapping.End = kv[1]
+		default:
+			return nil, fmt.Errorf("Invalid mapping field: %s", kv[0])
+		}
+	}
+	return &mapping, nil
+}
+
+// parseImportTime parses a time or a timestamp, whose seconds are truncated,
+// and returns the date of it if any. Timestamps are converted to location,
+// where ones without offset are taken as in it.
+func parseImportTime(value string, location *time.Location) (*Date, *Time, error) {
+	value = strings.TrimSpace(value)
+	toDateTime := func(t time.Time) (*Date, *Time) {
+		t = t.In(location)
+		return &Date{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}, &Time{Hour: t.Hour(), Minute: t.Minute()}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006/01/02 15:04:05", "2006/01/02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			date, hm := toDateTime(t)
+			return date, hm, nil
+		}
+	}
+	if strings.Count(value, ":") == 2 {
+		value = value[:strings.LastIndex(value, ":")]
+	}
+	t, err := ParseHHMM(value)
+	if err != nil || t.Hour < 0 || t.Hour > 23 || t.Minute < 0 || t.Minute > 59 {
+		return nil, nil, fmt.Errorf("Invalid time: %s", value)
+	}
+	return nil, t, nil
+}
+
+// locationCache returns time zones of projects, reading the config once per project.
+func locationCache(config *configuration.Config) func(projectName string) (*time.Location, error) {
+	locations := map[string]*time.Location{}
+	return func(projectName string) (*time.Location, error) {
+		if location, ok := locations[projectName]; ok {
+			return location, nil
+		}
+		location, err := GetLocation(config, projectName)
+		if err != nil {
+			return nil, err
+		}
+		locations[projectName] = location
+		return location, nil
+	}
+}
+
+func parseImportDate(value string) (*Date, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "2006/01/02", "2006/1/2"} {
+		if d, err := time.Parse(layout, value); err == nil {
+			return &Date{Year: d.Year(), Month: int(d.Month()), Day: d.Day()}, nil
+		}
+	}
+	return nil, fmt.Errorf("Invalid date: %s", value)
+}
+
+// ReadImportCSV reads periods from CSV. Rows without start are skipped, such as
+// travel expenses in CSV written by Export. All rows are of projectName if it
+// is not empty. Timestamps are converted to the time zone of the project.
+func ReadImportCSV(r io.Reader, mapping *CSVMapping, projectName string, config *configuration.Config) ([]ImportEntry, error) {
+	getLocation := locationCache(config)
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimPrefix(name, "\ufeff")] = i
+	}
+	column := func(name string, required bool) (int, error) {
+		if i, ok := columns[name]; ok {
+			return i, nil
+		}
+		if required {
+			return -1, fmt.Errorf("Column not found: %s", name)
+		}
+		return -1, nil
+	}
+
+	projectCol, err := column(mapping.Project, projectName == "")
+	if err != nil {
+		return nil, err
+	}
+	dateCol, err := column(mapping.Date, false)
+	if err != nil {
+		return nil, err
+	}
+	startCol, err := column(mapping.Start, true)
+	if err != nil {
+		return nil, err
+	}
+	endCol, err := column(mapping.End, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ImportEntry
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to read CSV: %w", err)
+		}
+		cell := func(col int) string {
+			if col < 0 || col >= len(row) {
+				return ""
+			}
+			return row[col]
+		}
+		if strings.TrimSpace(cell(startCol)) == "" {
+			continue
+		}
+
+		entry := ImportEntry{ProjectName: projectName}
+		if entry.ProjectName == "" {
+			entry.ProjectName = cell(projectCol)
+		}
+		location, err := getLocation(entry.ProjectName)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %v", line, err)
+		}
+		startDate, start, err := parseImportTime(cell(startCol), location)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %v", line, err)
+		}
+		entry.Start = start
+		if cell(endCol) != "" {
+			if _, entry.End, err = parseImportTime(cell(endCol), location); err != nil {
+				return nil, fmt.Errorf("Line %d: %v", line, err)
+			}
+		}
+		if dateCol >= 0 && cell(dateCol) != "" {
+			if entry.Date, err = parseImportDate(cell(dateCol)); err != nil {
+				return nil, fmt.Errorf("Line %d: %v", line, err)
+			}
+		} else if startDate != nil {
+			entry.Date = startDate
+		} else {
+			return nil, fmt.Errorf("Line %d: date not found", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ReadImportJSON reads periods and travel expenses from JSON written by Export.
+// Periods are of the date of the start in the time zone of the project.
+func ReadImportJSON(r io.Reader, config *configuration.Config) ([]ImportEntry, error) {
+	getLocation := locationCache(config)
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, xerrors.Errorf("Unable to read JSON: %w", err)
+	}
+	if export.Version != ExportVersion {
+		return nil, fmt.Errorf("Unsupported version: %d", export.Version)
+	}
+
+	var entries []ImportEntry
+	for _, record := range export.Records {
+		date, err := parseImportDate(record.Date)
+		if err != nil {
+			return nil, err
+		}
+		location, err := getLocation(record.Project)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range record.Periods {
+			entry := ImportEntry{ProjectName: record.Project, Date: date}
+			startDate, start, err := parseImportTime(p.Start, location)
+			if err != nil {
+				return nil, err
+			}
+			if startDate != nil {
+				entry.Date = startDate
+			}
+			entry.Start = start
+			if p.End != nil {
+				if _, entry.End, err = parseImportTime(*p.End, location); err != nil {
+					return nil, err
+				}
+			}
+			entries = append(entries, entry)
+		}
+		if t := record.TravelExpense; t != nil {
+			entries = append(entries, ImportEntry{
+				ProjectName:   record.Project,
+				Date:          date,
+				TravelExpense: &TravelExpense{Expense: t.Expense, Note: t.Note},
+			})
+		}
+	}
+	return entries, nil
+}
+
+// Actions of ImportChange.
+const (
+	ImportAdd      = "add"
+	ImportSkip     = "skip"
+	ImportConflict = "conflict"
+)
+
+// ImportChange is what is done for an entry. Slot is the 0-based index of the
+// period to be written by ImportAdd.
+type ImportChange struct {
+	Entry  ImportEntry
+	Action string
+	Slot   int
+	Reason string
+}
+
+func (this *ImportChange) String() string {
+	mark := map[string]string{ImportAdd: "+", ImportSkip: "=", ImportConflict: "!"}[this.Action]
+	s := fmt.Sprintf("%s %s", mark, this.Entry.String())
+	if this.Action == ImportAdd && this.Entry.TravelExpense == nil {
+		s += fmt.Sprintf(" #%d", this.Slot+1)
+	}
+	if this.Reason != "" {
+		s += ": " + this.Reason
+	}
+	return s
+}
+
+// getPeriodCapacity returns the number of periods which a day of the project
+// can have, or -1 if the storage adds periods as needed.
+func (this *WorkTime) getPeriodCapacity(projectName string) (int, error) {
+	kind, err := this.config.GetStorage(projectName)
+	if err != nil || kind != "spreadsheet" {
+		return -1, err
+	}
+	layout, err := getLayout(this.config, projectName)
+	if err != nil {
+		return -1, err
+	}
+	return len(layout.PeriodColumns), nil
+}
+
+// PlanImport compares entries with the records and decides what to do for
+// each of them. Entries which are already recorded are skipped, and ones which
+// overlap a recorded period or another entry, whose travel expense differs
+// from the recorded one, or which find no free slot in the layout are
+// conflicts. New periods take empty slots first.
+func (this *WorkTime) PlanImport(entries []ImportEntry) ([]ImportChange, error) {
+	entries = append([]ImportEntry{}, entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := &entries[i], &entries[j]
+		if a.ProjectName != b.ProjectName {
+			return a.ProjectName < b.ProjectName
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.Start == nil || b.Start == nil {
+			return b.Start == nil && a.Start != nil
+		}
+		return a.Start.Hour*60+a.Start.Minute < b.Start.Hour*60+b.Start.Minute
+	})
+
+	months := map[string]*MonthlyWorkTime{}
+	getRecord := func(projectName string, date *Date) (*WorkTimeRecord, error) {
+		key := projectName + "/" + getSheetName(date.Year, date.Month)
+		monthlyWorkTime, ok := months[key]
+		if !ok {
+			var err error
+			if monthlyWorkTime, err = this.Get(projectName, date.Year, date.Month); err != nil {
+				return nil, err
+			}
+			months[key] = monthlyWorkTime
+		}
+		for i := range monthlyWorkTime.Records {
+			if monthlyWorkTime.Records[i].Date.Equal(date) {
+				return &monthlyWorkTime.Records[i], nil
+			}
+		}
+		return nil, xerrors.Errorf("%s on %v: %w", projectName, date, ErrNotFound)
+	}
+
+	var changes []ImportChange
+	for _, entry := range entries {
+		if _, err := this.config.FindSpreadsheet(entry.ProjectName); err != nil {
+			return nil, err
+		}
+		record, err := getRecord(entry.ProjectName, entry.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		change := ImportChange{Entry: entry, Action: ImportAdd, Slot: -1}
+		if entry.TravelExpense != nil {
+			if t := record.TravelExpense; t != nil {
+				if *t == *entry.TravelExpense {
+					change.Action = ImportSkip
+				} else {
+					change.Action = ImportConflict
+					change.Reason = fmt.Sprintf("travel %d %s is recorded", t.Expense, t.Note)
+				}
+			} else {
+				record.TravelExpense = entry.TravelExpense
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		location, err := GetLocation(this.config, entry.ProjectName)
+		if err != nil {
+			return nil, err
+		}
+		period, err := parsePeriod(entry.Date, formatTime(entry.Start), formatTime(entry.End), location)
+		if err != nil {
+			return nil, xerrors.Errorf("Invalid period of %s: %w", entry.String(), err)
+		}
+
+		for i, p := range record.Periods {
+			if p.IsEmpty() {
+				if change.Slot == -1 {
+					change.Slot = i
+				}
+				continue
+			}
+			if p.Start.Equal(period.Start) && p.End.Equal(period.End) {
+				change.Action = ImportSkip
+				break
+			}
+			if overlaps(&p, period) || (p.IsEndEmpty() && period.IsEndEmpty()) {
+				change.Action = ImportConflict
+				change.Reason = fmt.Sprintf("overlaps %s of #%d", formatPeriod(&p), i+1)
+				break
+			}
+		}
+		if change.Action == ImportAdd && change.Slot == -1 {
+			capacity, err := this.getPeriodCapacity(entry.ProjectName)
+			if err != nil {
+				return nil, err
+			}
+			if capacity >= 0 && len(record.Periods) >= capacity {
+				change.Action = ImportConflict
+				change.Reason = fmt.Sprintf("no free slot in %d period columns", capacity)
+			}
+		}
+		if change.Action == ImportAdd {
+			if change.Slot == -1 {
+				change.Slot = len(record.Periods)
+				record.Periods = append(record.Periods, Period{})
+			}
+			// Later entries are compared with this one
+			record.Periods[change.Slot] = *period
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// ApplyImport writes changes to be added in batches of at most batchSize
+// periods of a project in a month. progress is called after each batch.
+func (this *WorkTime) ApplyImport(changes []ImportChange, batchSize int, progress func(done int)) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("Invalid batch size: %d", batchSize)
+	}
+
+	done := 0
+	var batch []PeriodChange
+	batchProject := ""
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := this.storage.ReplacePeriods(batchProject, batch); err != nil {
+			return xerrors.Errorf("Unable to import periods: %w", err)
+		}
+		done += len(batch)
+		batch = nil
+		progress(done)
+		return nil
+	}
+
+	for _, c := range changes {
+		if c.Action != ImportAdd {
+			continue
+		}
+		e := &c.Entry
+		if e.TravelExpense != nil {
+			if err := this.storage.UpdateTravelExpense(e.ProjectName, e.Date, e.TravelExpense.Expense, e.TravelExpense.Note); err != nil {
+				return xerrors.Errorf("Unable to import travel expense: %w", err)
+			}
+			continue
+		}
+
+		if len(batch) > 0 && (len(batch) >= batchSize || batchProject != e.ProjectName ||
+			batch[0].Date.Year != e.Date.Year || batch[0].Date.Month != e.Date.Month) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		batchProject = e.ProjectName
+		batch = append(batch, PeriodChange{Date: e.Date, PeriodIndex: c.Slot, Start: e.Start, End: e.End})
+	}
+	return flush()
+}
@@ -0,0 +1,126 @@
+package worktime
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadImportCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		csv         string
+		mapping     string
+		projectName string
+		want        []string
+	}{
+		{"export", "project,date,start,end\na,2026-10-05,9:00,12:00\na,2026-10-05,,\n", "", "", []string{"a 2026-10-05 9:00-12:00"}},
+		{"mapping", "Day,From,To\n2026/10/05,09:00:00,18:30:00\n", "date=Day,start=From,end=To", "a", []string{"a 2026-10-05 9:00-18:30"}},
+		{"open", "project,date,start,end\na,2026-10-05,9:00,\n", "", "", []string{"a 2026-10-05 9:00-"}},
+		{"timestamps in UTC", "start,end\n2026-10-05T00:30:00Z,2026-10-05T09:00:00Z\n", "", "a", []string{"a 2026-10-05 9:30-18:00"}},
+		{"date of start in time zone", "start,end\n2026-10-04T23:00:00Z,2026-10-05T03:00:00Z\n", "", "a", []string{"a 2026-10-05 8:00-12:00"}},
+		{"time zone of project", "project,start,end\nb,2026-10-05T00:30:00Z,2026-10-05T02:00:00Z\n", "", "", []string{"b 2026-10-04 20:30-22:00"}},
+		{"local timestamps", "start,end\n2026-10-05 09:00,2026-10-05 17:45\n", "", "a", []string{"a 2026-10-05 9:00-17:45"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a", "b")
+			w.config.Spreadsheets[1].TimeZone = "America/New_York"
+			mapping, err := ParseCSVMapping(tt.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := ReadImportCSV(strings.NewReader(tt.csv), mapping, tt.projectName, w.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadImportCSVInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		mapping string
+	}{
+		{"missing column", "project,date,start\na,2026-10-05,9:00\n", ""},
+		{"unknown mapping", "project,date,start,end\n", "begin=Start"},
+		{"invalid time", "project,date,start,end\na,2026-10-05,25:00,26:00\n", ""},
+		{"no date", "project,start,end\na,9:00,12:00\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			mapping, err := ParseCSVMapping(tt.mapping)
+			if err == nil {
+				_, err = ReadImportCSV(strings.NewReader(tt.csv), mapping, "", w.config)
+			}
+			if err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	date := &Date{Year: 2026, Month: 10, Day: 5}
+	entry := func(start, end *Time) ImportEntry {
+		return ImportEntry{ProjectName: "a", Date: date, Start: start, End: end}
+	}
+	tests := []struct {
+		name    string
+		storage string
+		entries []ImportEntry
+		want    []string
+	}{
+		{"add", "memory", []ImportEntry{entry(hm(13, 0), hm(15, 0))}, []string{"add #2"}},
+		{"recorded", "memory", []ImportEntry{entry(hm(9, 0), hm(12, 0))}, []string{"skip"}},
+		{"overlap with record", "memory", []ImportEntry{entry(hm(11, 0), hm(14, 0))}, []string{"conflict"}},
+		{"overlap with entry", "memory", []ImportEntry{entry(hm(13, 0), hm(15, 0)), entry(hm(14, 0), hm(16, 0))}, []string{"add #2", "conflict"}},
+		{"travel", "memory", []ImportEntry{
+			{ProjectName: "a", Date: date, TravelExpense: &TravelExpense{Expense: 300, Note: "bus"}},
+			{ProjectName: "a", Date: date, TravelExpense: &TravelExpense{Expense: 500, Note: "train"}},
+		}, []string{"add #0", "conflict"}},
+		{"beyond layout", "spreadsheet", []ImportEntry{
+			entry(hm(13, 0), hm(14, 0)), entry(hm(15, 0), hm(16, 0)), entry(hm(17, 0), hm(18, 0)),
+		}, []string{"add #2", "add #3", "conflict"}},
+		{"added by storage", "memory", []ImportEntry{
+			entry(hm(13, 0), hm(14, 0)), entry(hm(15, 0), hm(16, 0)), entry(hm(17, 0), hm(18, 0)),
+		}, []string{"add #2", "add #3", "add #4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			w.config.Storage = tt.storage
+			if err := w.storage.ReplacePeriods("a", []PeriodChange{{date, 0, hm(9, 0), hm(12, 0)}}); err != nil {
+				t.Fatal(err)
+			}
+			changes, err := w.PlanImport(tt.entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range changes {
+				s := c.Action
+				if c.Action == ImportAdd {
+					s = fmt.Sprintf("%s #%d", s, c.Slot+1)
+				}
+				got = append(got, s)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("changes = %v, want %v", got, tt.want)
+			}
+			if err := w.ApplyImport(changes, 2, func(int) {}); err != nil {
+				t.Errorf("ApplyImport = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package worktime
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// JournalEntry is a mutation which could not be written to the storage.
+type JournalEntry struct {
+	Operation   string // "start", "end" or "travel"
+	ProjectName string
+	Date        Date
+	Time        *Time  `json:",omitempty"`
+	Expense     int    `json:",omitempty"`
+	Note        string `json:",omitempty"`
+	CreatedAt   time.Time
+}
+
+func (this *JournalEntry) String() string {
+	switch this.Operation {
+	case "start", "end":
+		return fmt.Sprintf("%s %s %v %d:%02d", this.Operation, this.ProjectName,
+			&this.Date, this.Time.Hour, this.Time.Minute)
+	default:
+		return fmt.Sprintf("%s %s %v %d %s", this.Operation, this.ProjectName,
+			&this.Date, this.Expense, this.Note)
+	}
+}
+
+// Journal is a queue of mutations persisted in a file, one JSON entry per line.
+type Journal struct {
+	path string
+}
+
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+func (this *Journal) Load() ([]JournalEntry, error) {
+	f, err := os.Open(this.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, xerrors.Errorf("Invalid journal entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("Unable to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+func (this *Journal) Append(entry *JournalEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return xerrors.Errorf("Unable to encode journal entry: %w", err)
+	}
+	f, err := os.OpenFile(this.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return xerrors.Errorf("Unable to open journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return xerrors.Errorf("Unable to write journal: %w", err)
+	}
+	return nil
+}
+
+// Save replaces the journal with entries. The file is removed if entries is empty.
+func (this *Journal) Save(entries []JournalEntry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(this.path); err != nil && !os.IsNotExist(err) {
+			return xerrors.Errorf("Unable to remove journal: %w", err)
+		}
+		return nil
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return xerrors.Errorf("Unable to encode journal entry: %w", err)
+		}
+		data = append(append(data, b...), '\n')
+	}
+
+	tmp := filepath.Join(filepath.Dir(this.path), "."+filepath.Base(this.path)+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return xerrors.Errorf("Unable to write journal: %w", err)
+	}
+	if err := os.Rename(tmp, this.path); err != nil {
+		return xerrors.Errorf("Unable to write journal: %w", err)
+	}
+	return nil
+}
+
+// ConflictError is returned when a journal entry conflicts with the current records.
+type ConflictError struct {
+	Entry  *JournalEntry
+	Reason string
+}
+
+func (this *ConflictError) Error() string {
+	return fmt.Sprintf("Conflict: %s: %s", this.Entry, this.Reason)
+}
+
+func isSameTime(t time.Time, tm *Time) bool {
+	return !t.IsZero() && t.Hour() == tm.Hour%24 && t.Minute() == tm.Minute
+}
+
+func getLastPeriod(record *WorkTimeRecord) *Period {
+	var last *Period
+	for i, p := range record.Periods {
+		if p.IsEmpty() {
+			break
+		}
+		last = &record.Periods[i]
+	}
+	return last
+}
+
+// isOvernightEndApplied reports whether the last period of the previous day
+// of the "end" entry has been ended at the time of the entry.
+func (this *WorkTime) isOvernightEndApplied(entry *JournalEntry) (bool, error) {
+	date := entry.Date.AddDays(-1)
+	record, err := this.getRecord(entry.ProjectName, date)
+	if err != nil && date.Month != entry.Date.Month && xerrors.Is(err, ErrMonthNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	last := getLastPeriod(record)
+	return last != nil && !last.IsEndEmpty() && last.End.Day() == entry.Date.Day && isSameTime(last.End, entry.Time), nil
+}
+
+// Replay applies entry to the storage after checking it against the current records.
+// Entries which have already been applied are ignored.
+func (this *WorkTime) Replay(entry *JournalEntry) error {
+	record, err := this.getRecord(entry.ProjectName, &entry.Date)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Operation {
+	case "start":
+		for _, p := range record.Periods {
+			if p.IsEmpty() {
+				break
+			}
+			if isSameTime(p.Start, entry.Time) {
+				return nil
+			}
+			if p.IsEndEmpty() {
+				return &ConflictError{Entry: entry, Reason: "another period has been started"}
+			}
+			end := p.End.Hour()*60 + p.End.Minute()
+			if p.End.Day() != p.Start.Day() {
+				end += 24 * 60
+			}
+			if end > entry.Time.Hour*60+entry.Time.Minute {
+				return &ConflictError{Entry: entry, Reason: "overlaps with a recorded period"}
+			}
+		}
+		return this.SetStart(entry.ProjectName, &entry.Date, entry.Time)
+	case "end":
+		last := getLastPeriod(record)
+		if last == nil || (!last.IsEndEmpty() && !isSameTime(last.End, entry.Time)) {
+			applied, err := this.isOvernightEndApplied(entry)
+			if err != nil || applied {
+				return err
+			}
+		}
+		if last == nil {
+			// It may end an overnight period of the previous day
+			err := this.SetEnd(entry.ProjectName, &entry.Date, entry.Time)
+			if xerrors.Is(err, ErrNotStarted) {
+				return &ConflictError{Entry: entry, Reason: "no period has been started"}
+			}
+			return err
+		}
+		if !last.IsEndEmpty() {
+			if isSameTime(last.End, entry.Time) {
+				return nil
+			}
+			return &ConflictError{Entry: entry, Reason: "the period has already been ended"}
+		}
+		return this.SetEnd(entry.ProjectName, &entry.Date, entry.Time)
+	case "travel":
+		if t := record.TravelExpense; t != nil {
+			if t.Expense == entry.Expense && t.Note == entry.Note {
+				return nil
+			}
+			return &ConflictError{Entry: entry, Reason: "a different travel expense has been recorded"}
+		}
+		return this.SetTravelExpense(entry.ProjectName, &entry.Date, entry.Expense, entry.Note)
+	default:
+		return fmt.Errorf("Invalid operation: %s", entry.Operation)
+	}
+}
@@ -0,0 +1,65 @@
+package worktime
+
+import (
+	"testing"
+)
+
+func TestReplayTwice(t *testing.T) {
+	day := func(d int) Date {
+		return Date{Year: 2026, Month: 10, Day: d}
+	}
+	tests := []struct {
+		name    string
+		entries []JournalEntry
+		date    Date
+		want    string
+	}{
+		{"day", []JournalEntry{
+			{Operation: "start", ProjectName: "a", Date: day(5), Time: hm(9, 0)},
+			{Operation: "end", ProjectName: "a", Date: day(5), Time: hm(18, 0)},
+		}, day(5), "9:00-18:00"},
+		{"overnight", []JournalEntry{
+			{Operation: "start", ProjectName: "a", Date: day(5), Time: hm(22, 0)},
+			{Operation: "end", ProjectName: "a", Date: day(6), Time: hm(2, 0)},
+		}, day(5), "22:00-2:00"},
+		{"across month", []JournalEntry{
+			{Operation: "start", ProjectName: "a", Date: day(31), Time: hm(22, 0)},
+			{Operation: "end", ProjectName: "a", Date: Date{Year: 2026, Month: 11, Day: 1}, Time: hm(2, 0)},
+		}, day(31), "22:00-2:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			for i := 0; i < 2; i++ {
+				for _, entry := range tt.entries {
+					if err := w.Replay(&entry); err != nil {
+						t.Fatalf("replay %d of %s: %v", i+1, &entry, err)
+					}
+				}
+			}
+
+			record, err := w.getRecord("a", &tt.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := formatPeriod(&record.Periods[0]); got != tt.want {
+				t.Errorf("period = %s, want %s", got, tt.want)
+			}
+			if !record.Periods[1].IsEmpty() {
+				t.Errorf("second period = %s", formatPeriod(&record.Periods[1]))
+			}
+		})
+	}
+}
+
+func TestReplayConflict(t *testing.T) {
+	w := newTestWorkTime("a")
+	date := Date{Year: 2026, Month: 10, Day: 5}
+	if err := w.EditPeriod("a", &date, 0, hm(9, 0), hm(18, 0)); err != nil {
+		t.Fatal(err)
+	}
+	entry := &JournalEntry{Operation: "end", ProjectName: "a", Date: date, Time: hm(17, 0)}
+	if _, ok := w.Replay(entry).(*ConflictError); !ok {
+		t.Errorf("Replay of %s is not a conflict", entry)
+	}
+}
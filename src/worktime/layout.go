@@ -0,0 +1,195 @@
+package worktime
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// Layout is the positions of values in a monthly sheet.
+// Columns are 0-based indexes from column A, and -1 means absence.
+type Layout struct {
+	FirstRow            int
+	DateColumn          int
+	PeriodColumns       [][2]int
+	DurationColumn      int
+	TravelNoteColumn    int
+	TravelExpenseColumn int
+}
+
+// DefaultLayout is the layout of the original template, whose range is A4:K40.
+var DefaultLayout = newGridLayout(3)
+
+// newGridLayout returns the layout of the template extended to n periods,
+// where the columns after the periods are shifted to the right.
+func newGridLayout(n int) *Layout {
+	layout := &Layout{
+		FirstRow:            4,
+		DateColumn:          0,
+		DurationColumn:      2 + 2*n,
+		TravelNoteColumn:    3 + 2*n,
+		TravelExpenseColumn: 4 + 2*n,
+	}
+	for i := 0; i < n; i++ {
+		layout.PeriodColumns = append(layout.PeriodColumns, [2]int{2 + 2*i, 3 + 2*i})
+	}
+	return layout
+}
+
+func parseColumn(name string) (int, error) {
+	if name == "-" {
+		return -1, nil
+	}
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return 0, fmt.Errorf("Empty column")
+	}
+	col := 0
+	for _, c := range name {
+		if c < 'A' || c > 'Z' {
+			return 0, fmt.Errorf("Invalid column: %s", name)
+		}
+		col = col*26 + int(c-'A') + 1
+	}
+	return col - 1, nil
+}
+
+func formatColumn(col int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name
+}
+
+func NewLayout(config *configuration.LayoutConfig) (*Layout, error) {
+	if config == nil {
+		return newGridLayout(len(DefaultLayout.PeriodColumns)), nil
+	}
+
+	if config.PeriodCount < 0 {
+		return nil, fmt.Errorf("Invalid period count: %d", config.PeriodCount)
+	}
+	// The columns after the periods follow the last pair by default
+	n := len(DefaultLayout.PeriodColumns)
+	if config.PeriodCount > 0 {
+		n = config.PeriodCount
+	} else if len(config.PeriodColumns) > 0 {
+		n = len(config.PeriodColumns)
+	}
+	layout := *newGridLayout(n)
+
+	if config.HeaderRow < 0 {
+		return nil, fmt.Errorf("Invalid header row: %d", config.HeaderRow)
+	}
+	if config.HeaderRow > 0 {
+		layout.FirstRow = config.HeaderRow + 1
+	}
+
+	columns := []struct {
+		name string
+		col  *int
+	}{
+		{config.DateColumn, &layout.DateColumn},
+		{config.DurationColumn, &layout.DurationColumn},
+		{config.TravelNoteColumn, &layout.TravelNoteColumn},
+		{config.TravelExpenseColumn, &layout.TravelExpenseColumn},
+	}
+	for _, c := range columns {
+		if c.name == "" {
+			continue
+		}
+		col, err := parseColumn(c.name)
+		if err != nil {
+			return nil, err
+		}
+		*c.col = col
+	}
+	if layout.DateColumn < 0 || layout.DurationColumn < 0 {
+		return nil, fmt.Errorf("Date and duration columns are required")
+	}
+	if (layout.TravelNoteColumn < 0) != (layout.TravelExpenseColumn < 0) {
+		return nil, fmt.Errorf("Travel note and expense columns must be given together")
+	}
+
+	if len(config.PeriodColumns) > 0 {
+		layout.PeriodColumns = nil
+		for _, pair := range config.PeriodColumns {
+			start, err := parseColumn(pair[0])
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseColumn(pair[1])
+			if err != nil {
+				return nil, err
+			}
+			if start < 0 || end < 0 {
+				return nil, fmt.Errorf("Invalid period columns: %v", pair)
+			}
+			layout.PeriodColumns = append(layout.PeriodColumns, [2]int{start, end})
+		}
+	}
+
+	// A column shared by values would be overwritten by another
+	used := map[int]bool{}
+	cols := []int{layout.DateColumn, layout.DurationColumn, layout.TravelNoteColumn, layout.TravelExpenseColumn}
+	for _, pair := range layout.PeriodColumns {
+		cols = append(cols, pair[0], pair[1])
+	}
+	for _, col := range cols {
+		if col < 0 {
+			continue
+		}
+		if used[col] {
+			return nil, fmt.Errorf("Column %s is used more than once", formatColumn(col))
+		}
+		used[col] = true
+	}
+
+	return &layout, nil
+}
+
+func getLayout(config *configuration.Config, projectName string) (*Layout, error) {
+	sheet, err := config.FindSpreadsheet(projectName)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := NewLayout(sheet.Layout)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid layout of %s: %w", projectName, err)
+	}
+	return layout, nil
+}
+
+func (this *Layout) hasTravelExpense() bool {
+	return this.TravelNoteColumn >= 0 && this.TravelExpenseColumn >= 0
+}
+
+// lastColumn returns the rightmost column which has any value.
+func (this *Layout) lastColumn() int {
+	last := this.DateColumn
+	cols := []int{this.DurationColumn, this.TravelNoteColumn, this.TravelExpenseColumn}
+	for _, pair := range this.PeriodColumns {
+		cols = append(cols, pair[0], pair[1])
+	}
+	for _, col := range cols {
+		if col > last {
+			last = col
+		}
+	}
+	return last
+}
+
+// getRange returns the range which covers all days and the total row.
+func (this *Layout) getRange() (string, string) {
+	return fmt.Sprintf("A%d", this.FirstRow),
+		fmt.Sprintf("%s%d", formatColumn(this.lastColumn()), this.FirstRow+36)
+}
+
+func (this *Layout) getCellAddress(recordIndex, col int) string {
+	return fmt.Sprintf("%s%d", formatColumn(col), this.FirstRow+recordIndex)
+}
@@ -0,0 +1,125 @@
+package worktime
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"work-time-logging/configuration"
+)
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *configuration.LayoutConfig
+		want   *Layout
+	}{
+		{"default", nil, &Layout{4, 0, [][2]int{{2, 3}, {4, 5}, {6, 7}}, 8, 9, 10}},
+		{"period count", &configuration.LayoutConfig{PeriodCount: 4},
+			&Layout{4, 0, [][2]int{{2, 3}, {4, 5}, {6, 7}, {8, 9}}, 10, 11, 12}},
+		{"four period columns", &configuration.LayoutConfig{PeriodColumns: [][2]string{{"C", "D"}, {"E", "F"}, {"G", "H"}, {"I", "J"}}},
+			&Layout{4, 0, [][2]int{{2, 3}, {4, 5}, {6, 7}, {8, 9}}, 10, 11, 12}},
+		{"custom", &configuration.LayoutConfig{HeaderRow: 1, DateColumn: "B", PeriodColumns: [][2]string{{"D", "E"}},
+			DurationColumn: "F", TravelNoteColumn: "-", TravelExpenseColumn: "-"},
+			&Layout{2, 1, [][2]int{{3, 4}}, 5, -1, -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewLayout(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("layout = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLayoutInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *configuration.LayoutConfig
+	}{
+		{"duration in periods", &configuration.LayoutConfig{
+			PeriodColumns: [][2]string{{"C", "D"}, {"E", "F"}, {"G", "H"}, {"I", "J"}}, DurationColumn: "I"}},
+		{"date in periods", &configuration.LayoutConfig{DateColumn: "C"}},
+		{"same period columns", &configuration.LayoutConfig{PeriodColumns: [][2]string{{"C", "D"}, {"D", "E"}}}},
+		{"travel without note", &configuration.LayoutConfig{TravelNoteColumn: "-"}},
+		{"negative period count", &configuration.LayoutConfig{PeriodCount: -1}},
+		{"invalid column", &configuration.LayoutConfig{DateColumn: "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if layout, err := NewLayout(tt.config); err == nil {
+				t.Errorf("layout = %+v", layout)
+			}
+		})
+	}
+}
+
+// TestLayoutRoundTrip writes periods at the cells which the spreadsheet
+// storage addresses and reads them back by the layout.
+func TestLayoutRoundTrip(t *testing.T) {
+	layout, err := NewLayout(&configuration.LayoutConfig{
+		PeriodColumns: [][2]string{{"C", "D"}, {"E", "F"}, {"G", "H"}, {"I", "J"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &SpreadsheetStorage{}
+	year, month := 2026, 10
+	date := &Date{Year: year, Month: month, Day: 5}
+	periods := [][2]*Time{{hm(9, 0), hm(10, 0)}, {hm(11, 0), hm(12, 0)}, {hm(13, 0), hm(14, 0)}, {hm(15, 0), hm(16, 30)}}
+
+	grid := make([][]string, 32)
+	for i := range grid {
+		grid[i] = make([]string, layout.lastColumn()+1)
+		grid[i][layout.DurationColumn] = "0:00"
+		if i < 31 {
+			grid[i][layout.DateColumn] = fmt.Sprintf("%d/%d", month, i+1)
+		}
+	}
+	cells := map[string]string{}
+	for i, p := range periods {
+		for j, startOrEnd := range []string{"start", "end"} {
+			address, err := storage.getPeriodCellAddress(layout, date.Day-1, i, startOrEnd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cells[address] = formatTime(p[j])
+			grid[date.Day-1][layout.PeriodColumns[i][j]] = formatTime(p[j])
+		}
+	}
+	if cells["J8"] != "16:30" || cells["C8"] != "9:00" {
+		t.Errorf("cells = %v", cells)
+	}
+	if _, err := storage.getPeriodCellAddress(layout, date.Day-1, 4, "start"); err == nil {
+		t.Errorf("a fifth period has a cell")
+	}
+	grid[date.Day-1][layout.DurationColumn] = "4:30"
+	grid[31][layout.DurationColumn] = "4:30"
+	grid[date.Day-1][layout.TravelNoteColumn] = "bus"
+	grid[date.Day-1][layout.TravelExpenseColumn] = "300"
+	if got := layout.getCellAddress(date.Day-1, layout.TravelExpenseColumn); got != "M8" {
+		t.Errorf("travel expense cell = %s, want M8", got)
+	}
+
+	monthly, err := parseMonthlyWorkTime(year, month, toSheetValues(grid), layout, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monthly.Diagnostics) > 0 {
+		t.Fatalf("diagnostics = %v", monthly.Diagnostics)
+	}
+	record := monthly.Records[date.Day-1]
+	for i, p := range periods {
+		if got := formatPeriod(&record.Periods[i]); got != formatTime(p[0])+"-"+formatTime(p[1]) {
+			t.Errorf("period %d = %s", i+1, got)
+		}
+	}
+	if te := record.TravelExpense; te == nil || te.Expense != 300 || te.Note != "bus" {
+		t.Errorf("travel expense = %+v", te)
+	}
+}
@@ -0,0 +1,57 @@
+package worktime
+
+import (
+	"time"
+)
+
+// MemoryStorage keeps work time records in memory.
+// Months which have never been written are returned as blank sheets.
+type MemoryStorage struct {
+	sheets map[string][][]string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{sheets: map[string][][]string{}}
+}
+
+func (this *MemoryStorage) getRows(projectName string, year, month int) [][]string {
+	key := projectName + "/" + getSheetName(year, month)
+	rows, ok := this.sheets[key]
+	if !ok {
+		rows = newSheetRows(year, month)
+		this.sheets[key] = rows
+	}
+	return rows
+}
+
+func (this *MemoryStorage) Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error) {
+	return parseSheetRows(year, month, this.getRows(projectName, year, month), location)
+}
+
+func (this *MemoryStorage) setPeriod(projectName string, date *Date, periodIndex int, times map[string]*Time) error {
+	rows, err := setSheetPeriod(date.Year, date.Month, this.getRows(projectName, date.Year, date.Month),
+		date, periodIndex, times)
+	if err != nil {
+		return err
+	}
+	this.sheets[projectName+"/"+getSheetName(date.Year, date.Month)] = rows
+	return nil
+}
+
+func (this *MemoryStorage) UpdatePeriod(projectName string, date *Date, periodIndex int, startOrEnd string, time *Time) error {
+	return this.setPeriod(projectName, date, periodIndex, map[string]*Time{startOrEnd: time})
+}
+
+func (this *MemoryStorage) ReplacePeriods(projectName string, changes []PeriodChange) error {
+	for _, c := range changes {
+		if err := this.setPeriod(projectName, c.Date, c.PeriodIndex, map[string]*Time{"start": c.Start, "end": c.End}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *MemoryStorage) UpdateTravelExpense(projectName string, date *Date, expense int, note string) error {
+	rows := this.getRows(projectName, date.Year, date.Month)
+	return setSheetTravelExpense(rows, date, expense, note)
+}
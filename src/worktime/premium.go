@@ -0,0 +1,241 @@
+package worktime
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// PremiumPolicy is how work of a project is split into buckets of rates.
+// Weeks start on Sunday.
+type PremiumPolicy struct {
+	DailyLimit  time.Duration
+	WeeklyLimit time.Duration
+	// NightStart and NightEnd are minutes from midnight
+	NightStart, NightEnd int
+	HolidayWeekdays      []time.Weekday
+	// Calendar gives holidays other than HolidayWeekdays if not nil
+	Calendar *Calendar
+}
+
+var DefaultPremiumPolicy = &PremiumPolicy{
+	DailyLimit:      8 * time.Hour,
+	WeeklyLimit:     40 * time.Hour,
+	NightStart:      22 * 60,
+	NightEnd:        5 * 60,
+	HolidayWeekdays: []time.Weekday{time.Sunday},
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"Sun": time.Sunday, "Mon": time.Monday, "Tue": time.Tuesday, "Wed": time.Wednesday,
+	"Thu": time.Thursday, "Fri": time.Friday, "Sat": time.Saturday,
+}
+
+func NewPremiumPolicy(config *configuration.PremiumConfig) (*PremiumPolicy, error) {
+	policy := *DefaultPremiumPolicy
+	if config == nil {
+		return &policy, nil
+	}
+
+	if config.DailyHours < 0 || config.DailyHours > 24 {
+		return nil, fmt.Errorf("Invalid daily hours: %d", config.DailyHours)
+	}
+	if config.DailyHours > 0 {
+		policy.DailyLimit = time.Duration(config.DailyHours) * time.Hour
+	}
+	if config.WeeklyHours < 0 || config.WeeklyHours > 7*24 {
+		return nil, fmt.Errorf("Invalid weekly hours: %d", config.WeeklyHours)
+	}
+	if config.WeeklyHours > 0 {
+		policy.WeeklyLimit = time.Duration(config.WeeklyHours) * time.Hour
+	}
+
+	for _, n := range []struct {
+		value   string
+		minutes *int
+	}{
+		{config.NightStart, &policy.NightStart},
+		{config.NightEnd, &policy.NightEnd},
+	} {
+		if n.value == "" {
+			continue
+		}
+		t, err := ParseHHMM(n.value)
+		if err != nil || t.Hour < 0 || t.Hour > 23 || t.Minute < 0 || t.Minute > 59 {
+			return nil, fmt.Errorf("Invalid night hour: %s", n.value)
+		}
+		*n.minutes = t.Hour*60 + t.Minute
+	}
+
+	if config.HolidayWeekdays != nil {
+		policy.HolidayWeekdays = nil
+		for _, name := range config.HolidayWeekdays {
+			w, ok := weekdayNames[name]
+			if !ok {
+				return nil, fmt.Errorf("Invalid weekday: %s", name)
+			}
+			policy.HolidayWeekdays = append(policy.HolidayWeekdays, w)
+		}
+	}
+	return &policy, nil
+}
+
+func GetPremiumPolicy(config *configuration.Config, projectName string) (*PremiumPolicy, error) {
+	sheet, err := config.FindSpreadsheet(projectName)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := NewPremiumPolicy(sheet.Premium)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid premium of %s: %w", projectName, err)
+	}
+	policy.Calendar, err = GetCalendar(config, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (this *PremiumPolicy) IsHoliday(date *Date) bool {
+	for _, w := range this.HolidayWeekdays {
+		if date.GetWeekday() == w {
+			return true
+		}
+	}
+	if this.Calendar != nil {
+		_, holiday := this.Calendar.GetHoliday(date)
+		return holiday
+	}
+	return false
+}
+
+func (this *PremiumPolicy) isNight(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if this.NightStart > this.NightEnd {
+		return m >= this.NightStart || m < this.NightEnd
+	}
+	return m >= this.NightStart && m < this.NightEnd
+}
+
+// Breakdown is work split into exclusive buckets. Work on holidays is holiday
+// work, and the other is late-night work in the night hours, or overtime
+// beyond the daily or weekly limit, or regular work.
+type Breakdown struct {
+	Regular   time.Duration
+	Overtime  time.Duration
+	LateNight time.Duration
+	Holiday   time.Duration
+}
+
+func (this *Breakdown) add(other *Breakdown) {
+	this.Regular += other.Regular
+	this.Overtime += other.Overtime
+	this.LateNight += other.LateNight
+	this.Holiday += other.Holiday
+}
+
+// deduct removes a deducted break from overtime, regular, late-night and
+// holiday work in this order.
+func (this *Breakdown) deduct(d time.Duration) {
+	for _, bucket := range []*time.Duration{&this.Overtime, &this.Regular, &this.LateNight, &this.Holiday} {
+		n := d
+		if n > *bucket {
+			n = *bucket
+		}
+		*bucket -= n
+		d -= n
+	}
+}
+
+type DailyBreakdown struct {
+	Date *Date
+	Breakdown
+}
+
+type MonthlyBreakdown struct {
+	Year, Month int
+	Days        []DailyBreakdown
+	Total       Breakdown
+}
+
+// Calculate splits work of the records by the minute. Records before the
+// month in the same week are counted toward the weekly limit only.
+func (this *PremiumPolicy) Calculate(year, month int, records []WorkTimeRecord) *MonthlyBreakdown {
+	records = append([]WorkTimeRecord{}, records...)
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Date.Before(records[j].Date)
+	})
+
+	result := &MonthlyBreakdown{Year: year, Month: month}
+	var weekly time.Duration
+	for _, record := range records {
+		if record.Date.GetWeekday() == time.Sunday {
+			weekly = 0
+		}
+		holiday := this.IsHoliday(record.Date)
+
+		var periods []Period
+		for _, p := range record.Periods {
+			if !p.IsEmpty() && !p.IsEndEmpty() {
+				periods = append(periods, p)
+			}
+		}
+		sort.Slice(periods, func(i, j int) bool {
+			return periods[i].Start.Before(periods[j].Start)
+		})
+
+		day := DailyBreakdown{Date: record.Date}
+		var daily time.Duration
+		for _, p := range periods {
+			for t := p.Start; t.Before(p.End); t = t.Add(time.Minute) {
+				switch {
+				case holiday:
+					day.Holiday += time.Minute
+					continue
+				case this.isNight(t):
+					day.LateNight += time.Minute
+				case daily >= this.DailyLimit || weekly >= this.WeeklyLimit:
+					day.Overtime += time.Minute
+				default:
+					day.Regular += time.Minute
+				}
+				daily += time.Minute
+				weekly += time.Minute
+			}
+		}
+		day.deduct(record.BreakDeduction)
+
+		if record.Date.Year == year && record.Date.Month == month {
+			result.Days = append(result.Days, day)
+			result.Total.add(&day.Breakdown)
+		}
+	}
+	return result
+}
+
+// GetBreakdown returns work of the month split into buckets of rates, where
+// work of the week before the month counts toward the weekly limit if the
+// previous month exists.
+func (this *WorkTime) GetBreakdown(projectName string, year, month int) (*MonthlyBreakdown, error) {
+	policy, err := GetPremiumPolicy(this.config, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	first := &Date{Year: year, Month: month, Day: 1}
+	weekStart := first.AddDays(-int(first.GetWeekday()))
+	last := &Date{Year: year, Month: month, Day: 31}
+	records, _, err := this.getRecords(projectName, weekStart, last)
+	if err != nil && weekStart.Month != month && xerrors.Is(err, ErrMonthNotFound) {
+		// The first month of a sheet has no work carried over to the week
+		records, _, err = this.getRecords(projectName, first, last)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy.Calculate(year, month, records), nil
+}
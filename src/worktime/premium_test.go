@@ -0,0 +1,91 @@
+package worktime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPremiumPolicyCalculate(t *testing.T) {
+	// 2026-10-04 is Sunday
+	day := func(d int) *Date {
+		return &Date{Year: 2026, Month: 10, Day: d}
+	}
+	tests := []struct {
+		name    string
+		records func(t *testing.T) []WorkTimeRecord
+		want    Breakdown
+	}{
+		{"regular", func(t *testing.T) []WorkTimeRecord {
+			return []WorkTimeRecord{newTestRecord(t, day(5), [2]string{"9:00", "17:00"})}
+		}, Breakdown{Regular: 8 * time.Hour}},
+		{"daily overtime", func(t *testing.T) []WorkTimeRecord {
+			return []WorkTimeRecord{newTestRecord(t, day(5), [2]string{"9:00", "12:00"}, [2]string{"13:00", "20:00"})}
+		}, Breakdown{Regular: 8 * time.Hour, Overtime: 2 * time.Hour}},
+		{"late night", func(t *testing.T) []WorkTimeRecord {
+			return []WorkTimeRecord{newTestRecord(t, day(5), [2]string{"20:00", "1:00"})}
+		}, Breakdown{Regular: 2 * time.Hour, LateNight: 3 * time.Hour}},
+		{"holiday", func(t *testing.T) []WorkTimeRecord {
+			return []WorkTimeRecord{newTestRecord(t, day(4), [2]string{"9:00", "19:00"})}
+		}, Breakdown{Holiday: 10 * time.Hour}},
+		{"weekly overtime", func(t *testing.T) []WorkTimeRecord {
+			var records []WorkTimeRecord
+			for d := 5; d <= 10; d++ {
+				records = append(records, newTestRecord(t, day(d), [2]string{"9:00", "17:00"}))
+			}
+			return records
+		}, Breakdown{Regular: 40 * time.Hour, Overtime: 8 * time.Hour}},
+		{"break deduction", func(t *testing.T) []WorkTimeRecord {
+			record := newTestRecord(t, day(5), [2]string{"9:00", "19:00"})
+			record.BreakDeduction = time.Hour
+			return []WorkTimeRecord{record}
+		}, Breakdown{Regular: 8 * time.Hour, Overtime: time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultPremiumPolicy.Calculate(2026, 10, tt.records(t)).Total
+			if got != tt.want {
+				t.Errorf("total = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBreakdownCarryOver(t *testing.T) {
+	// 2026-10-01 is Thursday, whose week starts on 2026-09-27
+	tests := []struct {
+		name         string
+		missing      bool
+		wantOvertime time.Duration
+	}{
+		{"previous month", false, 8 * time.Hour},
+		{"missing previous month", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			memory := NewMemoryStorage()
+			w.storage = memory
+			if tt.missing {
+				w.storage = &missingMonthStorage{MemoryStorage: memory, first: &Date{Year: 2026, Month: 10, Day: 1}}
+			}
+			first := &Date{Year: 2026, Month: 9, Day: 27}
+			for d := 0; d < 7; d++ {
+				date := first.AddDays(d)
+				if err := memory.ReplacePeriods("a", []PeriodChange{{date, 0, hm(9, 0), hm(17, 0)}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			breakdown, err := w.GetBreakdown("a", 2026, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := breakdown.Total.Overtime; got != tt.wantOvertime {
+				t.Errorf("overtime = %v, want %v", got, tt.wantOvertime)
+			}
+			if got := breakdown.Total.Regular + breakdown.Total.Overtime; got != 24*time.Hour {
+				t.Errorf("regular and overtime = %v, want 24h", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package worktime
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// ProjectStorage dispatches to the storage configured for each project.
+// Storages are created on first use so that e.g. authorization of
+// Google Sheets is not required for projects stored in local files.
+type ProjectStorage struct {
+	config     *configuration.Config
+	newStorage func(kind string) (Storage, error)
+	storages   map[string]Storage
+}
+
+func NewProjectStorage(config *configuration.Config, newStorage func(kind string) (Storage, error)) *ProjectStorage {
+	return &ProjectStorage{config: config, newStorage: newStorage, storages: map[string]Storage{}}
+}
+
+func (this *ProjectStorage) getStorage(projectName string) (Storage, error) {
+	kind, err := this.config.GetStorage(projectName)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to get storage: %w", err)
+	}
+	if s, ok := this.storages[kind]; ok {
+		return s, nil
+	}
+	s, err := this.newStorage(kind)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to create storage: %w", err)
+	}
+	this.storages[kind] = s
+	return s, nil
+}
+
+func (this *ProjectStorage) Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error) {
+	s, err := this.getStorage(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(projectName, year, month, location)
+}
+
+func (this *ProjectStorage) UpdatePeriod(projectName string, date *Date, periodIndex int, startOrEnd string, time *Time) error {
+	s, err := this.getStorage(projectName)
+	if err != nil {
+		return err
+	}
+	return s.UpdatePeriod(projectName, date, periodIndex, startOrEnd, time)
+}
+
+func (this *ProjectStorage) ReplacePeriods(projectName string, changes []PeriodChange) error {
+	s, err := this.getStorage(projectName)
+	if err != nil {
+		return err
+	}
+	return s.ReplacePeriods(projectName, changes)
+}
+
+func (this *ProjectStorage) UpdateTravelExpense(projectName string, date *Date, expense int, note string) error {
+	s, err := this.getStorage(projectName)
+	if err != nil {
+		return err
+	}
+	return s.UpdateTravelExpense(projectName, date, expense, note)
+}
+
+func (this *ProjectStorage) GetRecords(projectName string, from, to *Date, location *time.Location) ([]WorkTimeRecord, error) {
+	s, err := this.getStorage(projectName)
+	if err != nil {
+		return nil, err
+	}
+	rs, ok := s.(RangeStorage)
+	if !ok {
+		return nil, ErrRangeUnsupported
+	}
+	return rs.GetRecords(projectName, from, to, location)
+}
@@ -2,7 +2,6 @@ package worktime
 
 import (
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +12,8 @@ import (
 type MonthlyWorkTime struct {
 	Year, Month int
 	Records     []WorkTimeRecord
+	// Diagnostics are problems of rows which are skipped or doubtful
+	Diagnostics []Diagnostic
 }
 
 func (this *MonthlyWorkTime) GetDuration() time.Duration {
@@ -23,13 +24,43 @@ func (this *MonthlyWorkTime) GetDuration() time.Duration {
 	return sum
 }
 
+func (this *MonthlyWorkTime) GetBreakDeduction() time.Duration {
+	var sum time.Duration
+	for _, r := range this.Records {
+		sum += r.BreakDeduction
+	}
+	return sum
+}
+
+func (this *MonthlyWorkTime) getWallDuration() time.Duration {
+	var sum time.Duration
+	for _, r := range this.Records {
+		sum += r.getWallDuration()
+	}
+	return sum
+}
+
 type WorkTimeRecord struct {
 	Date          *Date
 	Periods       []Period
 	TravelExpense *TravelExpense
+	// BreakShortage is how much the breaks are shorter than required,
+	// and BreakDeduction is the part of it deducted from the duration
+	BreakShortage  time.Duration
+	BreakDeduction time.Duration
 }
 
+// GetDuration returns the duration of work after deduction of breaks.
 func (this *WorkTimeRecord) GetDuration() time.Duration {
+	sum := this.getWorkDuration() - this.BreakDeduction
+	if sum < 0 {
+		return 0
+	}
+	return sum
+}
+
+// getWorkDuration returns the sum of periods.
+func (this *WorkTimeRecord) getWorkDuration() time.Duration {
 	var sum time.Duration
 	for _, p := range this.Periods {
 		sum += p.GetDuration()
@@ -37,6 +68,16 @@ func (this *WorkTimeRecord) GetDuration() time.Duration {
 	return sum
 }
 
+// getWallDuration returns the duration on wall clock, which differs from
+// GetDuration across a DST transition and is what formulas of sheets give.
+func (this *WorkTimeRecord) getWallDuration() time.Duration {
+	var sum time.Duration
+	for _, p := range this.Periods {
+		sum += p.getWallDuration()
+	}
+	return sum
+}
+
 type Period struct {
 	Start time.Time
 	End   time.Time
@@ -58,6 +99,16 @@ func (this *Period) GetDuration() time.Duration {
 	}
 }
 
+func (this *Period) getWallDuration() time.Duration {
+	if this.IsEmpty() || this.IsEndEmpty() {
+		return 0
+	}
+	wall := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return wall(this.End).Sub(wall(this.Start))
+}
+
 type TravelExpense struct {
 	Expense int
 	Note    string
@@ -90,7 +141,7 @@ func parseDate(year, month int, value string) (*Date, error) {
 	return &Date{Year: year, Month: m, Day: d}, nil
 }
 
-func parseTime(date *Date, value string) (time.Time, error) {
+func parseTime(date *Date, value string, location *time.Location) (time.Time, error) {
 	slice := strings.Split(value, ":")
 	if len(slice) != 2 {
 		return time.Time{}, fmt.Errorf("Invalid time: %s", value)
@@ -103,14 +154,13 @@ func parseTime(date *Date, value string) (time.Time, error) {
 	if err != nil {
 		return time.Time{}, fmt.Errorf("Invalid minute: %s", slice[1])
 	}
-	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
-	if err != nil {
-		return time.Time{}, err
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return time.Time{}, fmt.Errorf("Invalid time: %s", value)
 	}
-	return time.Date(date.Year, time.Month(date.Month), date.Day, h, m, 0, 0, jst), nil
+	return time.Date(date.Year, time.Month(date.Month), date.Day, h, m, 0, 0, location), nil
 }
 
-func parsePeriod(date *Date, start, end string) (*Period, error) {
+func parsePeriod(date *Date, start, end string, location *time.Location) (*Period, error) {
 	if start == "" {
 		if end == "" {
 			return &Period{Start: time.Time{}, End: time.Time{}}, nil
@@ -118,7 +168,7 @@ func parsePeriod(date *Date, start, end string) (*Period, error) {
 			return nil, fmt.Errorf("%v's start is empty but end is present", date)
 		}
 	} else {
-		s, err := parseTime(date, start)
+		s, err := parseTime(date, start, location)
 		if err != nil {
 			return nil, err
 		}
@@ -126,7 +176,7 @@ func parsePeriod(date *Date, start, end string) (*Period, error) {
 		if end == "" {
 			return &Period{Start: s, End: time.Time{}}, nil
 		} else {
-			e, err := parseTime(date, end)
+			e, err := parseTime(date, end, location)
 			if err != nil {
 				return nil, err
 			}
@@ -135,7 +185,7 @@ func parsePeriod(date *Date, start, end string) (*Period, error) {
 				e = e.AddDate(0, 0, 1)
 			}
 			if s.After(e) {
-				log.Fatalf("Invalid period: %v - %v", s, e)
+				return nil, fmt.Errorf("Invalid period: %s - %s", start, end)
 			}
 
 			return &Period{Start: s, End: e}, nil
@@ -159,30 +209,69 @@ func parseDuration(d string) (time.Duration, error) {
 	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
 }
 
-func parseWorkTimeRecord(year, month int, row []string) (*WorkTimeRecord, error) {
-	if len(row) < 9 {
-		return nil, xerrors.Errorf("Invalid row: %v", row)
+// Diagnostic is a problem of a cell found while parsing a sheet.
+type Diagnostic struct {
+	// Row is the 1-based row number in the sheet
+	Row int
+	// Column is the column name such as "C", or empty if the whole row is concerned
+	Column string
+	Value  string
+	Reason string
+}
+
+func (this *Diagnostic) String() string {
+	return fmt.Sprintf("%s%d %q: %s", this.Column, this.Row, this.Value, this.Reason)
+}
+
+// ParseError is returned by strict parsing if there are any diagnostics.
+type ParseError struct {
+	ProjectName string
+	Year, Month int
+	Diagnostics []Diagnostic
+}
+
+func (this *ParseError) Error() string {
+	return fmt.Sprintf("Invalid work time data of %s in %04d-%02d: %s",
+		this.ProjectName, this.Year, this.Month, this.Diagnostics[0].String())
+}
+
+// parseWorkTimeRecord returns the record of a row and problems of it. The
+// record is nil if the row is unusable, but it is returned with a diagnostic
+// if only the duration mismatches, which is calculated by a formula.
+func parseWorkTimeRecord(year, month int, row []string, rowNumber int, layout *Layout, location *time.Location) (*WorkTimeRecord, []Diagnostic) {
+	cell := func(col int) string {
+		if col < 0 || col >= len(row) {
+			return ""
+		}
+		return row[col]
+	}
+	diagnose := func(col int, err error) []Diagnostic {
+		return []Diagnostic{{Row: rowNumber, Column: formatColumn(col), Value: cell(col), Reason: err.Error()}}
 	}
 
-	date, err := parseDate(year, month, string(row[0]))
+	date, err := parseDate(year, month, cell(layout.DateColumn))
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to parse date: %w", err)
+		return nil, diagnose(layout.DateColumn, err)
 	}
 
 	var periods []Period
-	for _, i := range []int{2, 4, 6} {
-		p, err := parsePeriod(date, string(row[i]), string(row[i+1]))
+	for _, pair := range layout.PeriodColumns {
+		p, err := parsePeriod(date, cell(pair[0]), cell(pair[1]), location)
 		if err != nil {
-			return nil, xerrors.Errorf("Unable to parse period: %w", err)
+			col := pair[0]
+			if _, startErr := parsePeriod(date, cell(pair[0]), "", location); startErr == nil {
+				col = pair[1]
+			}
+			return nil, diagnose(col, err)
 		}
 		periods = append(periods, *p)
 	}
 
 	var travelExpense *TravelExpense
-	if len(row) > 10 {
-		travelExpense, err = parseTravelExpense(row[10], row[9])
+	if layout.hasTravelExpense() && cell(layout.TravelExpenseColumn) != "" {
+		travelExpense, err = parseTravelExpense(cell(layout.TravelExpenseColumn), cell(layout.TravelNoteColumn))
 		if err != nil {
-			return nil, xerrors.Errorf("Unable to parse travel expense: %w", err)
+			return nil, diagnose(layout.TravelExpenseColumn, err)
 		}
 	} else {
 		travelExpense = nil
@@ -195,57 +284,94 @@ func parseWorkTimeRecord(year, month int, row []string) (*WorkTimeRecord, error)
 	}
 
 	// Validate duration
-	sumActual := record.GetDuration()
-	sumGiven, err := parseDuration(row[8])
+	sumActual := record.getWallDuration()
+	sumGiven, err := parseDuration(cell(layout.DurationColumn))
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to parse duration: %w", err)
+		return record, diagnose(layout.DurationColumn, err)
 	}
 	if sumActual != sumGiven {
-		return nil, xerrors.Errorf("Duration mismatch: date=%v, given=%v, actual=%v", date, sumGiven, sumActual)
+		return record, diagnose(layout.DurationColumn,
+			fmt.Errorf("Duration mismatch: given=%v, actual=%v", sumGiven, sumActual))
 	}
 
 	return record, nil
 }
 
-func parseMonthlyWorkTime(year, month int, rows [][]interface{}) (*MonthlyWorkTime, error) {
-	var records []WorkTimeRecord
-	for i, rawRow := range rows {
-		var row []string
-		for _, v := range rawRow {
-			if s, ok := v.(string); ok {
-				row = append(row, s)
-			} else {
-				return nil, xerrors.Errorf("Invalid row: %v", rawRow)
+// parseMonthlyWorkTime returns the records of rows which are usable, with
+// diagnostics of the others. The total row is validated only if all rows are
+// valid.
+func parseMonthlyWorkTime(year, month int, rows [][]interface{}, layout *Layout, location *time.Location) (*MonthlyWorkTime, error) {
+	days := 0
+	for d := (&Date{Year: year, Month: month, Day: 1}); d.Month == month; d = d.AddDays(1) {
+		days++
+	}
+
+	monthlyWorkTime := &MonthlyWorkTime{Year: year, Month: month}
+	// toStrings returns false if the row is not found or has a value not a string
+	toStrings := func(i int) ([]string, bool) {
+		if i >= len(rows) {
+			return nil, false
+		}
+		row := []string{}
+		for col, v := range rows[i] {
+			s, ok := v.(string)
+			if !ok {
+				monthlyWorkTime.Diagnostics = append(monthlyWorkTime.Diagnostics, Diagnostic{
+					Row: layout.FirstRow + i, Column: formatColumn(col), Value: fmt.Sprint(v), Reason: "Not a string"})
+				return nil, false
 			}
+			row = append(row, s)
 		}
+		return row, true
+	}
 
-		record, err := parseWorkTimeRecord(year, month, row)
-		if err != nil {
-			return nil, xerrors.Errorf("Unable to parse work time record: %w", err)
+	for i := 0; i < days; i++ {
+		row, ok := toStrings(i)
+		if !ok {
+			if i >= len(rows) {
+				monthlyWorkTime.Diagnostics = append(monthlyWorkTime.Diagnostics,
+					Diagnostic{Row: layout.FirstRow + i, Reason: "Row not found"})
+			}
+			continue
 		}
+		if len(row) == 0 {
+			monthlyWorkTime.Diagnostics = append(monthlyWorkTime.Diagnostics,
+				Diagnostic{Row: layout.FirstRow + i, Reason: "Row is empty"})
+			continue
+		}
+		record, diagnostics := parseWorkTimeRecord(year, month, row, layout.FirstRow+i, layout, location)
+		monthlyWorkTime.Diagnostics = append(monthlyWorkTime.Diagnostics, diagnostics...)
+		if record != nil {
+			monthlyWorkTime.Records = append(monthlyWorkTime.Records, *record)
+		}
+	}
 
-		records = append(records, *record)
-
-		if record.Date.IsLastDayOfMonth() {
-			// Validate duration
-			var sumActual time.Duration
-			for _, r := range records {
-				sumActual += r.GetDuration()
+	if len(monthlyWorkTime.Diagnostics) == 0 {
+		// Validate duration
+		totalRow := layout.FirstRow + days
+		row, ok := toStrings(days)
+		if !ok || len(row) == 0 {
+			if days >= len(rows) {
+				monthlyWorkTime.Diagnostics = append(monthlyWorkTime.Diagnostics,
+					Diagnostic{Row: totalRow, Reason: "Total row not found"})
 			}
-			s, ok := rows[i+1][8].(string)
-			if !ok {
-				return nil, xerrors.Errorf("Invalid row: %v", rows[i+1])
+		} else {
+			value := ""
+			if layout.DurationColumn < len(row) {
+				value = row[layout.DurationColumn]
 			}
-			sumGiven, err := parseDuration(s)
-			if err != nil {
-				return nil, xerrors.Errorf("Unable to parse total duration: %w", err)
+			diagnose := func(reason string) {
+				monthlyWorkTime.Diagnostics = append(monthlyWorkTime.Diagnostics, Diagnostic{
+					Row: totalRow, Column: formatColumn(layout.DurationColumn), Value: value, Reason: reason})
 			}
-			if sumActual != sumGiven {
-				return nil, xerrors.Errorf("Total duration mismatch: given=%v, actual=%v", sumActual, sumGiven)
+			sumGiven, err := parseDuration(value)
+			if err != nil {
+				diagnose(err.Error())
+			} else if sumActual := monthlyWorkTime.getWallDuration(); sumActual != sumGiven {
+				diagnose(fmt.Sprintf("Total duration mismatch: given=%v, actual=%v", sumGiven, sumActual))
 			}
-
-			break
 		}
 	}
-	return &MonthlyWorkTime{Year: year, Month: month, Records: records}, nil
+
+	return monthlyWorkTime, nil
 }
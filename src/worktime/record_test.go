@@ -0,0 +1,76 @@
+package worktime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMonthlyWorkTimeEmptyRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		day     int
+		reasons []string
+	}{
+		{"none empty", 0, nil},
+		{"first day", 1, []string{"Row is empty"}},
+		{"last day", 30, []string{"Row is empty"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := toSheetValues(newSheetRows(2026, 9))
+			if tt.day > 0 {
+				rows[tt.day-1] = []interface{}{}
+			}
+			monthly, err := parseMonthlyWorkTime(2026, 9, rows, DefaultLayout, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var reasons []string
+			for _, d := range monthly.Diagnostics {
+				reasons = append(reasons, d.Reason)
+				if d.Row != DefaultLayout.FirstRow+tt.day-1 {
+					t.Errorf("row = %d, want %d", d.Row, DefaultLayout.FirstRow+tt.day-1)
+				}
+			}
+			if len(reasons) != len(tt.reasons) || (len(reasons) > 0 && reasons[0] != tt.reasons[0]) {
+				t.Errorf("reasons = %v, want %v", reasons, tt.reasons)
+			}
+			if want := 30 - len(tt.reasons); len(monthly.Records) != want {
+				t.Errorf("records = %d, want %d", len(monthly.Records), want)
+			}
+		})
+	}
+}
+
+func TestParseMonthlyWorkTimeInvalidTime(t *testing.T) {
+	tests := []struct {
+		start, end string
+		column     int
+	}{
+		{"99:00", "18:00", DefaultLayout.PeriodColumns[0][0]},
+		{"9:00", "40:75", DefaultLayout.PeriodColumns[0][1]},
+		{"9:60", "18:00", DefaultLayout.PeriodColumns[0][0]},
+		{"-1:00", "18:00", DefaultLayout.PeriodColumns[0][0]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.start+"-"+tt.end, func(t *testing.T) {
+			sheetRows := newSheetRows(2026, 9)
+			sheetRows[0][DefaultLayout.PeriodColumns[0][0]] = tt.start
+			sheetRows[0][DefaultLayout.PeriodColumns[0][1]] = tt.end
+			monthly, err := parseMonthlyWorkTime(2026, 9, toSheetValues(sheetRows), DefaultLayout, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(monthly.Diagnostics) != 1 {
+				t.Fatalf("diagnostics = %v, want 1", monthly.Diagnostics)
+			}
+			d := monthly.Diagnostics[0]
+			if d.Row != DefaultLayout.FirstRow || d.Column != formatColumn(tt.column) {
+				t.Errorf("diagnostic at %s%d, want %s%d", d.Column, d.Row, formatColumn(tt.column), DefaultLayout.FirstRow)
+			}
+			if len(monthly.Records) != 29 {
+				t.Errorf("records = %d, want 29", len(monthly.Records))
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package worktime
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+// RoundingPolicy is how times of start and end of a project are rounded.
+type RoundingPolicy struct {
+	Unit       int
+	Start, End string
+}
+
+var DefaultRoundingPolicy = &RoundingPolicy{Unit: 10, Start: "up", End: "up"}
+
+func NewRoundingPolicy(config *configuration.RoundingConfig) (*RoundingPolicy, error) {
+	policy := *DefaultRoundingPolicy
+	if config == nil {
+		return &policy, nil
+	}
+
+	if config.Unit < 0 || config.Unit > 24*60 {
+		return nil, fmt.Errorf("Invalid rounding unit: %d", config.Unit)
+	}
+	if config.Unit > 0 {
+		policy.Unit = config.Unit
+	}
+	for _, d := range []struct {
+		value     string
+		direction *string
+	}{
+		{config.Start, &policy.Start},
+		{config.End, &policy.End},
+	} {
+		switch d.value {
+		case "":
+		case "up", "down", "nearest", "none":
+			*d.direction = d.value
+		default:
+			return nil, fmt.Errorf("Invalid rounding direction: %s", d.value)
+		}
+	}
+	return &policy, nil
+}
+
+func GetRoundingPolicy(config *configuration.Config, projectName string) (*RoundingPolicy, error) {
+	sheet, err := config.FindSpreadsheet(projectName)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := NewRoundingPolicy(sheet.Rounding)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid rounding of %s: %w", projectName, err)
+	}
+	return policy, nil
+}
+
+// RoundStart rounds a start, which stays in the day at 23:59 at the latest.
+func (this *RoundingPolicy) RoundStart(t *Time) *Time {
+	rounded := t.Round(this.Unit, this.Start)
+	if rounded.Hour >= 24 {
+		return &Time{Hour: 23, Minute: 59}
+	}
+	return rounded
+}
+
+// RoundEnd rounds an end, where 24:00 is 0:00 of the next day.
+func (this *RoundingPolicy) RoundEnd(t *Time) *Time {
+	rounded := t.Round(this.Unit, this.End)
+	if rounded.Hour >= 24 {
+		return &Time{Hour: rounded.Hour - 24, Minute: rounded.Minute}
+	}
+	return rounded
+}
+
+// RoundingLogEntry is a time punched by a user and the time written after rounding.
+type RoundingLogEntry struct {
+	Operation   string // "start" or "end"
+	ProjectName string
+	Date        Date
+	Raw         Time
+	Rounded     Time
+}
+
+// RoundingLog keeps punched times in a file, one JSON entry per line, since
+// storages have only rounded times.
+type RoundingLog struct {
+	path string
+}
+
+func NewRoundingLog(path string) *RoundingLog {
+	return &RoundingLog{path: path}
+}
+
+func (this *RoundingLog) Append(entry *RoundingLogEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return xerrors.Errorf("Unable to encode rounding log: %w", err)
+	}
+	f, err := os.OpenFile(this.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return xerrors.Errorf("Unable to open rounding log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return xerrors.Errorf("Unable to write rounding log: %w", err)
+	}
+	return nil
+}
+
+// findRoundedPeriod returns the day of the record which has the period
+// started or ended at the rounded time of the entry. An end can be of an
+// overnight period of the previous day.
+func findRoundedPeriod(monthlyWorkTime *MonthlyWorkTime, entry *RoundingLogEntry) (int, bool) {
+	for _, record := range monthlyWorkTime.Records {
+		overnight := entry.Operation == "end" && record.Date.Equal(entry.Date.AddDays(-1))
+		if !record.Date.Equal(&entry.Date) && !overnight {
+			continue
+		}
+		for _, p := range record.Periods {
+			if p.IsEndEmpty() {
+				continue
+			}
+			if entry.Operation == "start" && isSameTime(p.Start, &entry.Rounded) {
+				return record.Date.Day, true
+			}
+			if entry.Operation == "end" && isSameTime(p.End, &entry.Rounded) &&
+				(!overnight || p.End.Day() == entry.Date.Day) {
+				return record.Date.Day, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// GetAdjustments returns how much rounding has added to (or removed from if
+// negative) the duration of each day of the month, keyed by the day of the
+// record of the period. Entries whose rounded time is no longer in the
+// records are ignored.
+func (this *RoundingLog) GetAdjustments(projectName string, monthlyWorkTime *MonthlyWorkTime) (map[int]time.Duration, error) {
+	adjustments := map[int]time.Duration{}
+
+	f, err := os.Open(this.path)
+	if os.IsNotExist(err) {
+		return adjustments, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open rounding log: %w", err)
+	}
+	defer f.Close()
+
+	// The last entry wins if the same time is punched more than once
+	entries := map[RoundingLogEntry]time.Duration{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry RoundingLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, xerrors.Errorf("Invalid rounding log: %w", err)
+		}
+		// An end on the first day can be of an overnight period of the month
+		inMonth := func(d *Date) bool {
+			return d.Year == monthlyWorkTime.Year && d.Month == monthlyWorkTime.Month
+		}
+		if entry.ProjectName != projectName ||
+			!(inMonth(&entry.Date) || (entry.Operation == "end" && inMonth(entry.Date.AddDays(-1)))) {
+			continue
+		}
+		diff := time.Duration((entry.Rounded.Hour*60+entry.Rounded.Minute)-
+			(entry.Raw.Hour*60+entry.Raw.Minute)) * time.Minute
+		// An end rounded over midnight is of the next day
+		if diff < -12*time.Hour {
+			diff += 24 * time.Hour
+		}
+		entry.Raw = Time{}
+		entries[entry] = diff
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("Unable to read rounding log: %w", err)
+	}
+
+	for entry, diff := range entries {
+		if day, ok := findRoundedPeriod(monthlyWorkTime, &entry); ok {
+			if entry.Operation == "start" {
+				adjustments[day] -= diff
+			} else {
+				adjustments[day] += diff
+			}
+		}
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,132 @@
+package worktime
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimeRound(t *testing.T) {
+	tests := []struct {
+		time      *Time
+		unit      int
+		direction string
+		want      Time
+	}{
+		{hm(9, 1), 10, "up", Time{9, 10}},
+		{hm(9, 10), 10, "up", Time{9, 10}},
+		{hm(9, 59), 10, "up", Time{10, 0}},
+		{hm(9, 9), 10, "down", Time{9, 0}},
+		{hm(9, 7), 15, "nearest", Time{9, 0}},
+		{hm(9, 8), 15, "nearest", Time{9, 15}},
+		{hm(9, 29), 60, "nearest", Time{9, 0}},
+		{hm(9, 30), 60, "nearest", Time{10, 0}},
+		{hm(9, 7), 10, "none", Time{9, 7}},
+		{hm(9, 7), 1, "up", Time{9, 7}},
+	}
+	for _, tt := range tests {
+		if got := tt.time.Round(tt.unit, tt.direction); *got != tt.want {
+			t.Errorf("%s rounded %s to %d = %s, want %s", formatTime(tt.time), tt.direction, tt.unit, formatTime(got), formatTime(&tt.want))
+		}
+	}
+}
+
+func TestRoundingPolicyMidnight(t *testing.T) {
+	tests := []struct {
+		policy     RoundingPolicy
+		time       *Time
+		start, end Time
+	}{
+		{RoundingPolicy{Unit: 10, Start: "up", End: "up"}, hm(23, 55), Time{23, 59}, Time{0, 0}},
+		{RoundingPolicy{Unit: 60, Start: "nearest", End: "nearest"}, hm(23, 30), Time{23, 59}, Time{0, 0}},
+		{RoundingPolicy{Unit: 15, Start: "up", End: "up"}, hm(23, 46), Time{23, 59}, Time{0, 0}},
+		{RoundingPolicy{Unit: 15, Start: "down", End: "down"}, hm(23, 59), Time{23, 45}, Time{23, 45}},
+		{RoundingPolicy{Unit: 10, Start: "up", End: "up"}, hm(23, 45), Time{23, 50}, Time{23, 50}},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.RoundStart(tt.time); *got != tt.start {
+			t.Errorf("%+v: start %s = %s, want %s", tt.policy, formatTime(tt.time), formatTime(got), formatTime(&tt.start))
+		}
+		if got := tt.policy.RoundEnd(tt.time); *got != tt.end {
+			t.Errorf("%+v: end %s = %s, want %s", tt.policy, formatTime(tt.time), formatTime(got), formatTime(&tt.end))
+		}
+	}
+}
+
+func TestSetStartRoundedLateAtNight(t *testing.T) {
+	w := newTestWorkTime("a")
+	date := &Date{Year: 2026, Month: 10, Day: 5}
+	policy := &RoundingPolicy{Unit: 10, Start: "up", End: "up"}
+	if err := w.SetStart("a", date, policy.RoundStart(hm(23, 55))); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetEnd("a", date.AddDays(1), policy.RoundEnd(hm(0, 25))); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := w.getRecord("a", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := record.Periods[0]
+	if p.Start.Day() != date.Day || p.Start.Hour() != 23 || p.Start.Minute() != 59 {
+		t.Errorf("start = %v, want %v 23:59", p.Start, date)
+	}
+	if got := p.GetDuration().Minutes(); got != 31 {
+		t.Errorf("duration = %v minutes, want 31", got)
+	}
+
+	if err := w.SetStart("a", date, &Time{Hour: 24, Minute: 0}); err == nil {
+		t.Errorf("start at 24:00 succeeded")
+	}
+}
+
+func TestRoundingLogOvernightEnd(t *testing.T) {
+	day := func(d int) Date {
+		return Date{Year: 2026, Month: 10, Day: d}
+	}
+	tests := []struct {
+		name       string
+		start, end *Time
+		periodDay  int
+		entry      RoundingLogEntry
+		want       time.Duration
+	}{
+		{"same day", hm(9, 0), hm(18, 10), 5,
+			RoundingLogEntry{"end", "a", day(5), Time{18, 3}, Time{18, 10}}, 7 * time.Minute},
+		{"after midnight", hm(22, 0), hm(0, 10), 5,
+			RoundingLogEntry{"end", "a", day(6), Time{0, 5}, Time{0, 10}}, 5 * time.Minute},
+		{"rounded to midnight", hm(22, 0), hm(0, 0), 5,
+			RoundingLogEntry{"end", "a", day(5), Time{23, 55}, Time{0, 0}}, 5 * time.Minute},
+		{"across month", hm(22, 0), hm(1, 0), 31,
+			RoundingLogEntry{"end", "a", Date{Year: 2026, Month: 11, Day: 1}, Time{0, 52}, Time{1, 0}}, 8 * time.Minute},
+		{"start", hm(9, 10), hm(18, 0), 5,
+			RoundingLogEntry{"start", "a", day(5), Time{9, 4}, Time{9, 10}}, -6 * time.Minute},
+		{"not recorded", hm(22, 0), hm(0, 20), 5,
+			RoundingLogEntry{"end", "a", day(6), Time{0, 5}, Time{0, 10}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			date := day(tt.periodDay)
+			if err := w.storage.ReplacePeriods("a", []PeriodChange{{&date, 0, tt.start, tt.end}}); err != nil {
+				t.Fatal(err)
+			}
+			log := NewRoundingLog(filepath.Join(t.TempDir(), "rounding.jsonl"))
+			if err := log.Append(&tt.entry); err != nil {
+				t.Fatal(err)
+			}
+			monthlyWorkTime, err := w.Get("a", 2026, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			adjustments, err := log.GetAdjustments("a", monthlyWorkTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := adjustments[tt.periodDay]; got != tt.want {
+				t.Errorf("adjustment of day %d = %v, want %v (%v)", tt.periodDay, got, tt.want, adjustments)
+			}
+		})
+	}
+}
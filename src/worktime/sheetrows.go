@@ -0,0 +1,154 @@
+package worktime
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Sheet rows emulate the range A4:K40 of the spreadsheet template, which is
+// DefaultLayout, for storages other than Google Sheets, including the duration
+// column and the total row which are calculated by formulas in the template.
+// Pairs of period columns are added when all periods of a day are used.
+
+func formatSheetDuration(d time.Duration) string {
+	h := int(d.Hours())
+	m := int((d - d.Truncate(time.Hour)).Minutes())
+	return fmt.Sprintf("%d:%02d", h, m)
+}
+
+func newSheetRows(year, month int) [][]string {
+	layout := DefaultLayout
+	newRow := func() []string {
+		row := make([]string, layout.lastColumn()+1)
+		row[layout.DurationColumn] = formatSheetDuration(0)
+		return row
+	}
+
+	var rows [][]string
+	for day := 1; ; day++ {
+		date := &Date{Year: year, Month: month, Day: day}
+		row := newRow()
+		row[layout.DateColumn] = fmt.Sprintf("%d/%d", month, day)
+		row[layout.DateColumn+1] = []string{"日", "月", "火", "水", "木", "金", "土"}[date.GetWeekday()]
+		rows = append(rows, row)
+		if date.IsLastDayOfMonth() {
+			break
+		}
+	}
+	rows = append(rows, newRow())
+	return rows
+}
+
+// getSheetRowsLayout returns the layout of rows, whose width tells the number of periods.
+func getSheetRowsLayout(rows [][]string) *Layout {
+	return newGridLayout((len(rows[0]) - 5) / 2)
+}
+
+// addSheetPeriod inserts a pair of period columns after the last period.
+func addSheetPeriod(rows [][]string) {
+	col := getSheetRowsLayout(rows).DurationColumn
+	for i, row := range rows {
+		r := append([]string{}, row[:col]...)
+		r = append(r, "", "")
+		rows[i] = append(r, row[col:]...)
+	}
+}
+
+// recalculateSheetRows updates the duration column. Rows which have invalid
+// periods are left as they are, as the formula of the template gives an error.
+func recalculateSheetRows(year, month int, rows [][]string) error {
+	layout := getSheetRowsLayout(rows)
+	var total time.Duration
+rowLoop:
+	for i, row := range rows[:len(rows)-1] {
+		date := &Date{Year: year, Month: month, Day: i + 1}
+		var sum time.Duration
+		for _, pair := range layout.PeriodColumns {
+			p, err := parsePeriod(date, row[pair[0]], row[pair[1]], time.UTC)
+			if err != nil {
+				continue rowLoop
+			}
+			sum += p.getWallDuration()
+		}
+		row[layout.DurationColumn] = formatSheetDuration(sum)
+		total += sum
+	}
+	rows[len(rows)-1][layout.DurationColumn] = formatSheetDuration(total)
+	return nil
+}
+
+// toSheetValues converts rows into the form returned by the Sheets API,
+// which omits trailing empty cells.
+func toSheetValues(rows [][]string) [][]interface{} {
+	var values [][]interface{}
+	for _, row := range rows {
+		n := len(row)
+		for n > 0 && row[n-1] == "" {
+			n--
+		}
+		var v []interface{}
+		for _, cell := range row[:n] {
+			v = append(v, cell)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func parseSheetRows(year, month int, rows [][]string, location *time.Location) (*MonthlyWorkTime, error) {
+	monthlyWorkTime, err := parseMonthlyWorkTime(year, month, toSheetValues(rows), getSheetRowsLayout(rows), location)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse work time data: %w", err)
+	}
+	return monthlyWorkTime, nil
+}
+
+// setSheetPeriod writes times keyed by "start" or "end" to a copy of rows and
+// returns it, where nil clears the cell. A pair of period columns is added if
+// periodIndex is next to the last period.
+func setSheetPeriod(year, month int, rows [][]string, date *Date, periodIndex int, times map[string]*Time) ([][]string, error) {
+	if date.Day < 1 || date.Day >= len(rows) {
+		return nil, fmt.Errorf("Invalid day: %d", date.Day)
+	}
+
+	newRows := make([][]string, len(rows))
+	for i, row := range rows {
+		newRows[i] = append([]string{}, row...)
+	}
+
+	layout := getSheetRowsLayout(newRows)
+	if periodIndex == len(layout.PeriodColumns) {
+		addSheetPeriod(newRows)
+		layout = getSheetRowsLayout(newRows)
+	}
+	if periodIndex < 0 || periodIndex >= len(layout.PeriodColumns) {
+		return nil, fmt.Errorf("Invalid period index: %d", periodIndex)
+	}
+
+	for startOrEnd, time := range times {
+		col := layout.PeriodColumns[periodIndex][0]
+		if startOrEnd == "end" {
+			col = layout.PeriodColumns[periodIndex][1]
+		}
+		newRows[date.Day-1][col] = ""
+		if time != nil {
+			newRows[date.Day-1][col] = fmt.Sprintf("%d:%02d", time.Hour, time.Minute)
+		}
+	}
+	if err := recalculateSheetRows(year, month, newRows); err != nil {
+		return nil, err
+	}
+	return newRows, nil
+}
+
+func setSheetTravelExpense(rows [][]string, date *Date, expense int, note string) error {
+	if date.Day < 1 || date.Day >= len(rows) {
+		return fmt.Errorf("Invalid day: %d", date.Day)
+	}
+	layout := getSheetRowsLayout(rows)
+	rows[date.Day-1][layout.TravelNoteColumn] = note
+	rows[date.Day-1][layout.TravelExpenseColumn] = fmt.Sprintf("%d", expense)
+	return nil
+}
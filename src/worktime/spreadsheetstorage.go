@@ -0,0 +1,185 @@
+package worktime
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+	"work-time-logging/spreadsheet"
+)
+
+// SpreadsheetStorage stores work time records in Google Sheets.
+// Each month is a sheet which has one row per day from the first day,
+// and the positions of values are given by the layout of the project.
+type SpreadsheetStorage struct {
+	sheet  *spreadsheet.Spreadsheet
+	config *configuration.Config
+}
+
+func NewSpreadsheetStorage(sheet *spreadsheet.Spreadsheet, config *configuration.Config) *SpreadsheetStorage {
+	return &SpreadsheetStorage{sheet: sheet, config: config}
+}
+
+func (this *SpreadsheetStorage) newAPIError(operation, projectName string, date *Date, address string, err error) error {
+	if spreadsheet.IsTemporary(err) {
+		err = &UnavailableError{Err: err}
+	} else if spreadsheet.IsSheetNotFound(err) {
+		err = xerrors.Errorf("%w: %v", ErrMonthNotFound, err)
+	}
+	return &APIError{Operation: operation, ProjectName: projectName, Date: date, Address: address, Err: err}
+}
+
+func (this *SpreadsheetStorage) getPeriodCellAddress(layout *Layout, recordIndex, periodIndex int, startOrEnd string) (string, error) {
+	if periodIndex >= len(layout.PeriodColumns) {
+		return "", xerrors.Errorf("%w: %d period columns in the layout", ErrNoFreeSlot, len(layout.PeriodColumns))
+	}
+	if periodIndex < 0 {
+		return "", fmt.Errorf("Invalid period index: %d", periodIndex)
+	}
+	col := layout.PeriodColumns[periodIndex][0]
+	if startOrEnd == "end" {
+		col = layout.PeriodColumns[periodIndex][1]
+	}
+	return layout.getCellAddress(recordIndex, col), nil
+}
+
+func (this *SpreadsheetStorage) getTravelExpenseCellAddress(layout *Layout, recordIndex int) ([]string, error) {
+	if !layout.hasTravelExpense() {
+		return nil, fmt.Errorf("Travel expense columns are not in the layout")
+	}
+	return []string{
+		layout.getCellAddress(recordIndex, layout.TravelNoteColumn),
+		layout.getCellAddress(recordIndex, layout.TravelExpenseColumn),
+	}, nil
+}
+
+func (this *SpreadsheetStorage) Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error) {
+	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+	}
+	layout, err := getLayout(this.config, projectName)
+	if err != nil {
+		return nil, err
+	}
+	sheetName := getSheetName(year, month)
+	leftUpper, rightBottom := layout.getRange()
+	rows, err := this.sheet.Get(spreadsheetId, sheetName, leftUpper, rightBottom)
+	if err != nil {
+		return nil, this.newAPIError("read", projectName, nil,
+			fmt.Sprintf("%s!%s:%s", sheetName, leftUpper, rightBottom), err)
+	}
+	monthlyWorkTime, err := parseMonthlyWorkTime(year, month, rows, layout, location)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse work time data: %w", err)
+	}
+	return monthlyWorkTime, nil
+}
+
+func (this *SpreadsheetStorage) UpdatePeriod(projectName string, date *Date, periodIndex int, startOrEnd string, t *Time) error {
+	layout, err := getLayout(this.config, projectName)
+	if err != nil {
+		return err
+	}
+
+	addr, err := this.getPeriodCellAddress(layout, date.Day-1, periodIndex, startOrEnd)
+	if err != nil {
+		return err
+	}
+
+	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
+	if err != nil {
+		return xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+	}
+
+	value := ""
+	if t != nil {
+		value = fmt.Sprintf("%2d:%02d", t.Hour, t.Minute)
+	}
+
+	sheetName := getSheetName(date.Year, date.Month)
+	err = this.sheet.Update(spreadsheetId, sheetName, addr, value)
+	if err != nil {
+		return this.newAPIError("write", projectName, date, sheetName+"!"+addr, err)
+	}
+
+	return nil
+}
+
+func (this *SpreadsheetStorage) ReplacePeriods(projectName string, changes []PeriodChange) error {
+	year, month, err := getMonth(changes)
+	if err != nil {
+		return err
+	}
+	layout, err := getLayout(this.config, projectName)
+	if err != nil {
+		return err
+	}
+
+	var cells []spreadsheet.Cell
+	for _, change := range changes {
+		for _, c := range []struct {
+			startOrEnd string
+			t          *Time
+		}{{"start", change.Start}, {"end", change.End}} {
+			addr, err := this.getPeriodCellAddress(layout, change.Date.Day-1, change.PeriodIndex, c.startOrEnd)
+			if err != nil {
+				return err
+			}
+			value := ""
+			if c.t != nil {
+				value = fmt.Sprintf("%2d:%02d", c.t.Hour, c.t.Minute)
+			}
+			cells = append(cells, spreadsheet.Cell{Address: addr, Value: value})
+		}
+	}
+
+	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
+	if err != nil {
+		return xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+	}
+
+	sheetName := getSheetName(year, month)
+	err = this.sheet.BatchUpdate(spreadsheetId, sheetName, cells)
+	if err != nil {
+		var date *Date
+		if len(changes) == 1 {
+			date = changes[0].Date
+		}
+		return this.newAPIError("write", projectName, date,
+			fmt.Sprintf("%s!%s...%s", sheetName, cells[0].Address, cells[len(cells)-1].Address), err)
+	}
+
+	return nil
+}
+
+func (this *SpreadsheetStorage) UpdateTravelExpense(projectName string, date *Date, expense int, note string) error {
+	layout, err := getLayout(this.config, projectName)
+	if err != nil {
+		return err
+	}
+
+	addrList, err := this.getTravelExpenseCellAddress(layout, date.Day-1)
+	if err != nil {
+		return err
+	}
+
+	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
+	if err != nil {
+		return xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+	}
+
+	sheetName := getSheetName(date.Year, date.Month)
+	err = this.sheet.BatchUpdate(spreadsheetId, sheetName, []spreadsheet.Cell{
+		{Address: addrList[0], Value: note},
+		{Address: addrList[1], Value: expense},
+	})
+	if err != nil {
+		return this.newAPIError("write", projectName, date,
+			fmt.Sprintf("%s!%s,%s", sheetName, addrList[0], addrList[1]), err)
+	}
+
+	return nil
+}
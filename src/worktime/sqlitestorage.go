@@ -0,0 +1,298 @@
+package worktime
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/xerrors"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS periods (
+	project_id INTEGER NOT NULL REFERENCES projects(id),
+	date       TEXT NOT NULL,    -- YYYY-MM-DD
+	slot       INTEGER NOT NULL, -- 0-based period index in the day
+	start_time TEXT NOT NULL DEFAULT '', -- HH:MM
+	end_time   TEXT NOT NULL DEFAULT '', -- HH:MM, earlier than start_time if it is on the next day
+	PRIMARY KEY (project_id, date, slot)
+);
+CREATE TABLE IF NOT EXISTS travel_expenses (
+	project_id INTEGER NOT NULL REFERENCES projects(id),
+	date       TEXT NOT NULL, -- YYYY-MM-DD
+	expense    INTEGER NOT NULL,
+	note       TEXT NOT NULL,
+	PRIMARY KEY (project_id, date)
+);
+`
+
+// sqliteMinPeriods is the number of periods of a day even if fewer are stored,
+// as in the spreadsheet template.
+const sqliteMinPeriods = 3
+
+// SQLiteStorage stores work time records of all months in a SQLite database.
+// A day can have any number of periods.
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, xerrors.Errorf("Unable to create directory: %w", err)
+	}
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on")
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, xerrors.Errorf("Unable to create schema: %w", err)
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+func formatSQLiteDate(date *Date) string {
+	return fmt.Sprintf("%04d-%02d-%02d", date.Year, date.Month, date.Day)
+}
+
+func (this *SQLiteStorage) getProjectId(tx *sql.Tx, projectName string) (int64, error) {
+	_, err := tx.Exec("INSERT OR IGNORE INTO projects (name) VALUES (?)", projectName)
+	if err != nil {
+		return 0, xerrors.Errorf("Unable to insert project: %w", err)
+	}
+	var id int64
+	err = tx.QueryRow("SELECT id FROM projects WHERE name = ?", projectName).Scan(&id)
+	if err != nil {
+		return 0, xerrors.Errorf("Unable to select project: %w", err)
+	}
+	return id, nil
+}
+
+// GetRecords returns records from one date to another inclusive in a single query.
+// Days without any period or travel expense are omitted.
+func (this *SQLiteStorage) GetRecords(projectName string, from, to *Date, location *time.Location) ([]WorkTimeRecord, error) {
+	rows, err := this.db.Query(`
+		SELECT d.date, p.slot, p.start_time, p.end_time, t.expense, t.note
+		FROM (
+			SELECT project_id, date FROM periods
+			UNION
+			SELECT project_id, date FROM travel_expenses
+		) d
+		JOIN projects ON projects.id = d.project_id
+		LEFT JOIN periods p ON p.project_id = d.project_id AND p.date = d.date
+		LEFT JOIN travel_expenses t ON t.project_id = d.project_id AND t.date = d.date
+		WHERE projects.name = ? AND d.date BETWEEN ? AND ?
+		ORDER BY d.date, p.slot`,
+		projectName, formatSQLiteDate(from), formatSQLiteDate(to))
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to select records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []WorkTimeRecord
+	for rows.Next() {
+		var dateStr string
+		var slot, expense sql.NullInt64
+		var start, end, note sql.NullString
+		if err := rows.Scan(&dateStr, &slot, &start, &end, &expense, &note); err != nil {
+			return nil, xerrors.Errorf("Unable to scan record: %w", err)
+		}
+
+		var date Date
+		if _, err := fmt.Sscanf(dateStr, "%04d-%02d-%02d", &date.Year, &date.Month, &date.Day); err != nil {
+			return nil, xerrors.Errorf("Invalid date: %s", dateStr)
+		}
+
+		if len(records) == 0 || !records[len(records)-1].Date.Equal(&date) {
+			var travelExpense *TravelExpense
+			if expense.Valid {
+				travelExpense = &TravelExpense{Expense: int(expense.Int64), Note: note.String}
+			}
+			records = append(records, WorkTimeRecord{
+				Date:          &date,
+				Periods:       make([]Period, sqliteMinPeriods),
+				TravelExpense: travelExpense,
+			})
+		}
+		record := &records[len(records)-1]
+
+		if slot.Valid {
+			if slot.Int64 < 0 {
+				return nil, xerrors.Errorf("Invalid slot: date=%s, slot=%d", dateStr, slot.Int64)
+			}
+			for int64(len(record.Periods)) <= slot.Int64 {
+				record.Periods = append(record.Periods, Period{})
+			}
+			p, err := parsePeriod(record.Date, start.String, end.String, location)
+			if err != nil {
+				return nil, xerrors.Errorf("Unable to parse period: %w", err)
+			}
+			record.Periods[slot.Int64] = *p
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, xerrors.Errorf("Unable to select records: %w", err)
+	}
+	return records, nil
+}
+
+func (this *SQLiteStorage) Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error) {
+	first := &Date{Year: year, Month: month, Day: 1}
+	last := &Date{Year: year, Month: month, Day: 31}
+	stored, err := this.GetRecords(projectName, first, last, location)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []WorkTimeRecord
+	for day := 1; ; day++ {
+		date := &Date{Year: year, Month: month, Day: day}
+		if len(stored) > 0 && stored[0].Date.Equal(date) {
+			records = append(records, stored[0])
+			stored = stored[1:]
+		} else {
+			records = append(records, WorkTimeRecord{Date: date, Periods: make([]Period, sqliteMinPeriods)})
+		}
+		if date.IsLastDayOfMonth() {
+			break
+		}
+	}
+	return &MonthlyWorkTime{Year: year, Month: month, Records: records}, nil
+}
+
+func (this *SQLiteStorage) UpdatePeriod(projectName string, date *Date, periodIndex int, startOrEnd string, t *Time) error {
+	if periodIndex < 0 {
+		return fmt.Errorf("Invalid period index: %d", periodIndex)
+	}
+	col := "start_time"
+	if startOrEnd == "end" {
+		col = "end_time"
+	}
+
+	value := ""
+	if t != nil {
+		value = fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+	}
+
+	tx, err := this.db.Begin()
+	if err != nil {
+		return xerrors.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	projectId, err := this.getProjectId(tx, projectName)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+		INSERT INTO periods (project_id, date, slot, %[1]s) VALUES (?, ?, ?, ?)
+		ON CONFLICT (project_id, date, slot) DO UPDATE SET %[1]s = excluded.%[1]s`, col),
+		projectId, formatSQLiteDate(date), periodIndex, value)
+	if err != nil {
+		return &APIError{Operation: "write", ProjectName: projectName, Date: date, Address: "periods", Err: err}
+	}
+
+	var start, end string
+	err = tx.QueryRow("SELECT start_time, end_time FROM periods WHERE project_id = ? AND date = ? AND slot = ?",
+		projectId, formatSQLiteDate(date), periodIndex).Scan(&start, &end)
+	if err != nil {
+		return xerrors.Errorf("Unable to select period: %w", err)
+	}
+	if _, err := parsePeriod(date, start, end, time.UTC); err != nil {
+		return xerrors.Errorf("Invalid period: %w", err)
+	}
+	if start == "" && end == "" {
+		_, err = tx.Exec("DELETE FROM periods WHERE project_id = ? AND date = ? AND slot = ?",
+			projectId, formatSQLiteDate(date), periodIndex)
+		if err != nil {
+			return &APIError{Operation: "write", ProjectName: projectName, Date: date, Address: "periods", Err: err}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return xerrors.Errorf("Unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (this *SQLiteStorage) ReplacePeriods(projectName string, changes []PeriodChange) error {
+	format := func(t *Time) string {
+		if t == nil {
+			return ""
+		}
+		return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+	}
+	for _, c := range changes {
+		if c.PeriodIndex < 0 {
+			return fmt.Errorf("Invalid period index: %d", c.PeriodIndex)
+		}
+		if _, err := parsePeriod(c.Date, format(c.Start), format(c.End), time.UTC); err != nil {
+			return xerrors.Errorf("Invalid period: %w", err)
+		}
+	}
+
+	tx, err := this.db.Begin()
+	if err != nil {
+		return xerrors.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	projectId, err := this.getProjectId(tx, projectName)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		if c.Start == nil && c.End == nil {
+			_, err = tx.Exec("DELETE FROM periods WHERE project_id = ? AND date = ? AND slot = ?",
+				projectId, formatSQLiteDate(c.Date), c.PeriodIndex)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO periods (project_id, date, slot, start_time, end_time) VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (project_id, date, slot) DO UPDATE SET start_time = excluded.start_time, end_time = excluded.end_time`,
+				projectId, formatSQLiteDate(c.Date), c.PeriodIndex, format(c.Start), format(c.End))
+		}
+		if err != nil {
+			return &APIError{Operation: "write", ProjectName: projectName, Date: c.Date, Address: "periods", Err: err}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return xerrors.Errorf("Unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (this *SQLiteStorage) UpdateTravelExpense(projectName string, date *Date, expense int, note string) error {
+	tx, err := this.db.Begin()
+	if err != nil {
+		return xerrors.Errorf("Unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	projectId, err := this.getProjectId(tx, projectName)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO travel_expenses (project_id, date, expense, note) VALUES (?, ?, ?, ?)
+		ON CONFLICT (project_id, date) DO UPDATE SET expense = excluded.expense, note = excluded.note`,
+		projectId, formatSQLiteDate(date), expense, note)
+	if err != nil {
+		return &APIError{Operation: "write", ProjectName: projectName, Date: date, Address: "travel_expenses", Err: err}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return xerrors.Errorf("Unable to commit transaction: %w", err)
+	}
+	return nil
+}
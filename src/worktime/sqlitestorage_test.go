@@ -0,0 +1,61 @@
+package worktime
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"work-time-logging/configuration"
+)
+
+// countingStorage counts monthly reads of the storage.
+type countingStorage struct {
+	*SQLiteStorage
+	gets int
+}
+
+func (this *countingStorage) Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error) {
+	this.gets++
+	return this.SQLiteStorage.Get(projectName, year, month, location)
+}
+
+func TestSQLiteStorageExportRange(t *testing.T) {
+	s, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "worktime.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &countingStorage{SQLiteStorage: s}
+	config := &configuration.Config{ConfigFile: configuration.ConfigFile{
+		Storage:      "sqlite",
+		TimeZone:     "Asia/Tokyo",
+		Spreadsheets: []*configuration.SpreadsheetConfig{{Name: "a"}},
+	}}
+	w := New(storage, config)
+
+	dates := []*Date{{Year: 2025, Month: 1, Day: 6}, {Year: 2026, Month: 6, Day: 1}, {Year: 2026, Month: 10, Day: 30}}
+	for _, date := range dates {
+		if err := w.SetStart("a", date, hm(9, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.SetEnd("a", date, hm(17, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	storage.gets = 0
+	export, _, err := w.Export([]string{"a"}, &Date{Year: 2025, Month: 1, Day: 1}, &Date{Year: 2026, Month: 12, Day: 31})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storage.gets != 0 {
+		t.Errorf("read %d months, want a ranged read", storage.gets)
+	}
+	if len(export.Records) != len(dates) {
+		t.Fatalf("got %d records, want %d", len(export.Records), len(dates))
+	}
+	for i, record := range export.Records {
+		if record.Date != dates[i].String() || record.Minutes != 8*60 {
+			t.Errorf("record %d = %s %d minutes, want %v 480 minutes", i, record.Date, record.Minutes, dates[i])
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package worktime
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Storage is a backend which holds monthly work time records of projects.
+// Get interprets times in location. UpdatePeriod writes the start or end of
+// a period, and ReplacePeriods writes both of periods in a month at once,
+// where nil clears it.
+type Storage interface {
+	Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error)
+	UpdatePeriod(projectName string, date *Date, periodIndex int, startOrEnd string, time *Time) error
+	ReplacePeriods(projectName string, changes []PeriodChange) error
+	UpdateTravelExpense(projectName string, date *Date, expense int, note string) error
+}
+
+// RangeStorage is a storage which can read records across months at once.
+// GetRecords returns records from one date to another inclusive, omitting days
+// without any period or travel expense, or ErrRangeUnsupported if the storage
+// of the project cannot.
+type RangeStorage interface {
+	GetRecords(projectName string, from, to *Date, location *time.Location) ([]WorkTimeRecord, error)
+}
+
+var ErrRangeUnsupported = errors.New("Range is not supported")
+
+// PeriodChange is the start and end of a period to be written.
+type PeriodChange struct {
+	Date        *Date
+	PeriodIndex int
+	Start, End  *Time
+}
+
+// getMonth returns the month of changes, which must be the same.
+func getMonth(changes []PeriodChange) (int, int, error) {
+	if len(changes) == 0 {
+		return 0, 0, fmt.Errorf("No changes")
+	}
+	year, month := changes[0].Date.Year, changes[0].Date.Month
+	for _, c := range changes[1:] {
+		if c.Date.Year != year || c.Date.Month != month {
+			return 0, 0, fmt.Errorf("Changes across months: %v and %v", changes[0].Date, c.Date)
+		}
+	}
+	return year, month, nil
+}
+
+// UnavailableError is returned when a storage is temporarily unreachable.
+type UnavailableError struct {
+	Err error
+}
+
+func (this *UnavailableError) Error() string {
+	return fmt.Sprintf("Storage unavailable: %v", this.Err)
+}
+
+func (this *UnavailableError) Unwrap() error {
+	return this.Err
+}
+
+func getSheetName(year, month int) string {
+	return fmt.Sprintf("%04d%02d", year, month)
+}
@@ -0,0 +1,291 @@
+package worktime
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Kinds of issues found by Check.
+const (
+	IssueOverlap    = "overlap"
+	IssueTooLong    = "too-long"
+	IssueStaleOpen  = "stale-open"
+	IssueOutOfOrder = "out-of-order"
+	IssueParse      = "parse"
+)
+
+// Issue is a suspicious period in records.
+// Issues of parsing have neither date nor slot.
+type Issue struct {
+	Kind        string
+	ProjectName string
+	Date        *Date
+	// Slot is the 0-based index of the period in the day
+	Slot    int
+	Message string
+}
+
+func (this *Issue) String() string {
+	if this.Date == nil {
+		return fmt.Sprintf("%s: %s: %s", this.ProjectName, this.Kind, this.Message)
+	}
+	return fmt.Sprintf("%v %s #%d: %s: %s", this.Date, this.ProjectName, this.Slot+1, this.Kind, this.Message)
+}
+
+// projectPeriod is a non-empty period with where it is recorded.
+type projectPeriod struct {
+	ProjectName string
+	Date        *Date
+	Slot        int
+	Period      *Period
+}
+
+func formatPeriod(p *Period) string {
+	if p.IsEndEmpty() {
+		return fmt.Sprintf("%d:%02d-", p.Start.Hour(), p.Start.Minute())
+	}
+	return fmt.Sprintf("%d:%02d-%d:%02d", p.Start.Hour(), p.Start.Minute(), p.End.Hour(), p.End.Minute())
+}
+
+func (this *WorkTime) getMaxPeriodDuration() time.Duration {
+	return time.Duration(this.config.GetMaxPeriodHours()) * time.Hour
+}
+
+// getRangeRecords returns records of the project from one date to another
+// inclusive in a read of the range storage, where omitted days are filled.
+func (this *WorkTime) getRangeRecords(storage RangeStorage, projectName string, from, to *Date) ([]WorkTimeRecord, error) {
+	location, err := GetLocation(this.config, projectName)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := storage.GetRecords(projectName, from, to, location)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := GetBreakPolicy(this.config, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []WorkTimeRecord
+	for date := from; !to.Before(date); date = date.AddDays(1) {
+		if len(stored) > 0 && stored[0].Date.Equal(date) {
+			records = append(records, stored[0])
+			stored = stored[1:]
+		} else {
+			records = append(records, WorkTimeRecord{Date: date})
+		}
+	}
+	policy.Apply(&MonthlyWorkTime{Records: records})
+	return records, nil
+}
+
+// getRecords returns records of the project from one date to another
+// inclusive, reading each month once unless the storage reads the range at
+// once, and diagnostics of the months.
+func (this *WorkTime) getRecords(projectName string, from, to *Date) ([]WorkTimeRecord, []Diagnostic, error) {
+	if storage, ok := this.storage.(RangeStorage); ok {
+		records, err := this.getRangeRecords(storage, projectName, from, to)
+		if err == nil {
+			return records, nil, nil
+		}
+		if !xerrors.Is(err, ErrRangeUnsupported) {
+			return nil, nil, xerrors.Errorf("Unable to get work time data: %w", err)
+		}
+	}
+
+	var records []WorkTimeRecord
+	var diagnostics []Diagnostic
+	year, month := from.Year, from.Month
+	for year < to.Year || (year == to.Year && month <= to.Month) {
+		monthlyWorkTime, err := this.Get(projectName, year, month)
+		if err != nil {
+			return nil, nil, err
+		}
+		diagnostics = append(diagnostics, monthlyWorkTime.Diagnostics...)
+		for _, record := range monthlyWorkTime.Records {
+			if !record.Date.Before(from) && !to.Before(record.Date) {
+				records = append(records, record)
+			}
+		}
+		month++
+		if month > 12 {
+			year, month = year+1, 1
+		}
+	}
+	return records, diagnostics, nil
+}
+
+func appendPeriods(periods []projectPeriod, projectName string, records []WorkTimeRecord) []projectPeriod {
+	for _, record := range records {
+		for i := range record.Periods {
+			if !record.Periods[i].IsEmpty() {
+				periods = append(periods, projectPeriod{projectName, record.Date, i, &record.Periods[i]})
+			}
+		}
+	}
+	return periods
+}
+
+// getPeriods returns non-empty periods of all projects but exclude
+// from one date to another inclusive, with issues of parsing the months.
+// Projects lacking the months are regarded as having no periods if optional.
+func (this *WorkTime) getPeriods(exclude string, from, to *Date, optional bool) ([]projectPeriod, []Issue, error) {
+	var periods []projectPeriod
+	var issues []Issue
+	for _, sheet := range this.config.Spreadsheets {
+		if sheet.Name == exclude {
+			continue
+		}
+		records, diagnostics, err := this.getRecords(sheet.Name, from, to)
+		if err != nil && optional && xerrors.Is(err, ErrMonthNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, d := range diagnostics {
+			issues = append(issues, Issue{Kind: IssueParse, ProjectName: sheet.Name, Slot: -1, Message: d.String()})
+		}
+		periods = appendPeriods(periods, sheet.Name, records)
+	}
+	return periods, issues, nil
+}
+
+// guardPeriod returns an error if period of the project would be longer than
+// the maximum or overlap a period of another project. An open period of
+// another project is regarded as running for the maximum duration. Projects
+// lacking the months have no periods, and ones which cannot be read otherwise
+// are skipped with a warning.
+func (this *WorkTime) guardPeriod(projectName string, date *Date, period *Period) error {
+	max := this.getMaxPeriodDuration()
+	if period.GetDuration() > max {
+		return xerrors.Errorf("%s on %v: %s is longer than %v: %w",
+			projectName, date, formatPeriod(period), max, ErrTooLong)
+	}
+
+	// Periods of the previous day can last overnight, and ones of the next day
+	// can start before an overnight end. The day in another month is read
+	// only if such a period can reach the period.
+	from, to := date, date
+	midnight := time.Date(date.Year, time.Month(date.Month), date.Day, 0, 0, 0, 0, period.Start.Location())
+	if prev := date.AddDays(-1); prev.Month == date.Month || period.Start.Before(midnight.Add(max)) {
+		from = prev
+	}
+	if next := date.AddDays(1); next.Month == date.Month || (!period.IsEndEmpty() && period.End.After(midnight.AddDate(0, 0, 1))) {
+		to = next
+	}
+
+	inMonth := func(d *Date) *Date {
+		if d.Month != date.Month {
+			return date
+		}
+		return d
+	}
+
+	var others []projectPeriod
+	for _, sheet := range this.config.Spreadsheets {
+		if sheet.Name == projectName {
+			continue
+		}
+		records, _, err := this.getRecords(sheet.Name, from, to)
+		if xerrors.Is(err, ErrMonthNotFound) && (from.Month != date.Month || to.Month != date.Month) {
+			// Read only the month if the one next to it is missing
+			records, _, err = this.getRecords(sheet.Name, inMonth(from), inMonth(to))
+		}
+		if xerrors.Is(err, ErrMonthNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Warning: overlaps with %s are not checked: %v", sheet.Name, err)
+			continue
+		}
+		others = appendPeriods(others, sheet.Name, records)
+	}
+	for _, other := range others {
+		if overlaps(period, assumeEnd(other.Period, max)) {
+			return xerrors.Errorf("%s on %v: %s overlaps %s of %s on %v: %w", projectName, date,
+				formatPeriod(period), formatPeriod(other.Period), other.ProjectName, other.Date, ErrOverlap)
+		}
+	}
+	return nil
+}
+
+// Check loads the month of all projects and reports invalid rows, and periods
+// which overlap each other, are longer than the maximum, have been left open
+// or are not in order of time within a day. Issues are sorted by date, where
+// ones of parsing come first.
+func (this *WorkTime) Check(year, month int, now time.Time) ([]Issue, error) {
+	max := this.getMaxPeriodDuration()
+	from := &Date{Year: year, Month: month, Day: 1}
+	to := &Date{Year: year, Month: month, Day: 31}
+	// The previous day of the month can have an overnight period, unless the
+	// previous month does not exist
+	periods, _, err := this.getPeriods("", from.AddDays(-1), from.AddDays(-1), true)
+	if err != nil {
+		return nil, err
+	}
+	monthPeriods, issues, err := this.getPeriods("", from, to, false)
+	if err != nil {
+		return nil, err
+	}
+	periods = append(periods, monthPeriods...)
+
+	// Open periods are regarded as running until now
+	running := func(p *Period) *Period {
+		d := now.Sub(p.Start)
+		if d > max {
+			d = max
+		}
+		return assumeEnd(p, d)
+	}
+
+	add := func(kind string, p *projectPeriod, format string, args ...interface{}) {
+		if p.Date.Before(from) {
+			return
+		}
+		issues = append(issues, Issue{kind, p.ProjectName, p.Date, p.Slot, fmt.Sprintf(format, args...)})
+	}
+
+	for i := range periods {
+		p := &periods[i]
+		if p.Period.GetDuration() > max {
+			add(IssueTooLong, p, "%s is longer than %v", formatPeriod(p.Period), max)
+		}
+		if p.Period.IsEndEmpty() && now.Sub(p.Period.Start) > max {
+			add(IssueStaleOpen, p, "%s has not been ended", formatPeriod(p.Period))
+		}
+
+		var prev *projectPeriod
+		if i > 0 && periods[i-1].ProjectName == p.ProjectName && periods[i-1].Date.Equal(p.Date) {
+			prev = &periods[i-1]
+		}
+		if (prev == nil && p.Slot > 0) || (prev != nil && prev.Slot+1 != p.Slot) {
+			add(IssueOutOfOrder, p, "slot #%d before it is empty", p.Slot)
+		}
+		if prev != nil && !prev.Period.Start.Before(p.Period.Start) {
+			add(IssueOutOfOrder, p, "%s starts before %s of #%d",
+				formatPeriod(p.Period), formatPeriod(prev.Period), prev.Slot+1)
+		}
+
+		for j := range periods[:i] {
+			q := &periods[j]
+			if overlaps(running(p.Period), running(q.Period)) {
+				add(IssueOverlap, p, "%s overlaps %s of %s on %v",
+					formatPeriod(p.Period), formatPeriod(q.Period), q.ProjectName, q.Date)
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Date == nil || issues[j].Date == nil {
+			return issues[i].Date == nil && issues[j].Date != nil
+		}
+		return issues[i].Date.Before(issues[j].Date)
+	})
+	return issues, nil
+}
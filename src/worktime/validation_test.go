@@ -0,0 +1,108 @@
+package worktime
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// missingMonthStorage is a memory storage which lacks months before the first.
+type missingMonthStorage struct {
+	*MemoryStorage
+	first *Date
+}
+
+func (this *missingMonthStorage) Get(projectName string, year, month int, location *time.Location) (*MonthlyWorkTime, error) {
+	if year < this.first.Year || (year == this.first.Year && month < this.first.Month) {
+		return nil, xerrors.Errorf("Unable to read %s: %w", getSheetName(year, month), ErrMonthNotFound)
+	}
+	return this.MemoryStorage.Get(projectName, year, month, location)
+}
+
+func TestCheck(t *testing.T) {
+	type period struct {
+		project    string
+		day        int
+		slot       int
+		start, end *Time
+	}
+	tests := []struct {
+		name    string
+		periods []period
+		want    []string
+	}{
+		{"valid", []period{{"a", 5, 0, hm(9, 0), hm(12, 0)}, {"b", 5, 0, hm(13, 0), hm(15, 0)}}, nil},
+		{"overlap", []period{{"a", 5, 0, hm(9, 0), hm(12, 0)}, {"b", 5, 0, hm(11, 0), hm(13, 0)}}, []string{IssueOverlap}},
+		{"overnight overlap", []period{{"a", 4, 0, hm(22, 0), hm(2, 0)}, {"b", 5, 0, hm(1, 0), hm(3, 0)}}, []string{IssueOverlap}},
+		{"stale open", []period{{"a", 5, 0, hm(9, 0), nil}}, []string{IssueStaleOpen}},
+		{"gap", []period{{"a", 5, 1, hm(9, 0), hm(12, 0)}}, []string{IssueOutOfOrder}},
+		{"earlier start", []period{{"a", 5, 0, hm(13, 0), hm(15, 0)}, {"a", 5, 1, hm(9, 0), hm(12, 0)}}, []string{IssueOutOfOrder}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a", "b")
+			for _, p := range tt.periods {
+				date := &Date{Year: 2026, Month: 10, Day: p.day}
+				if err := w.storage.ReplacePeriods(p.project, []PeriodChange{{date, p.slot, p.start, p.end}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			now := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+			issues, err := w.Check(2026, 10, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kinds []string
+			for _, issue := range issues {
+				kinds = append(kinds, issue.Kind)
+			}
+			if len(kinds) != len(tt.want) || (len(kinds) > 0 && kinds[0] != tt.want[0]) {
+				t.Errorf("issues = %v, want %v", issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingPreviousMonth(t *testing.T) {
+	first := &Date{Year: 2026, Month: 10, Day: 1}
+	w := newTestWorkTime("a", "b")
+	w.storage = &missingMonthStorage{MemoryStorage: NewMemoryStorage(), first: first}
+
+	if _, err := w.Check(2026, 10, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Errorf("Check = %v", err)
+	}
+	if open, err := w.GetOpenPeriod("a", first); open != nil || err != nil {
+		t.Errorf("GetOpenPeriod = %v, %v", open, err)
+	}
+	if err := w.SetStart("a", first, hm(9, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetEnd("a", first, hm(12, 0)); err != nil {
+		t.Fatal(err)
+	}
+	endAt := func(projectName string) (*Date, *Time) {
+		return first, hm(13, 0)
+	}
+	if _, err := w.Switch("b", first, hm(13, 0), endAt); err != nil {
+		t.Errorf("Switch = %v", err)
+	}
+	if _, err := w.Get("a", 2026, 9); !xerrors.Is(err, ErrMonthNotFound) {
+		t.Errorf("Get of the missing month = %v", err)
+	}
+}
+
+func TestGuardPeriodMissingPreviousMonth(t *testing.T) {
+	first := &Date{Year: 2026, Month: 10, Day: 1}
+	w := newTestWorkTime("a", "b")
+	w.storage = &missingMonthStorage{MemoryStorage: NewMemoryStorage(), first: first}
+	if err := w.SetStart("b", first, hm(1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetEnd("b", first, hm(3, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetStart("a", first, hm(2, 0)); !xerrors.Is(err, ErrOverlap) {
+		t.Errorf("SetStart = %v, want %v", err, ErrOverlap)
+	}
+}
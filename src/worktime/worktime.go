@@ -2,188 +2,342 @@ package worktime
 
 import (
 	"fmt"
+	"time"
 
 	"golang.org/x/xerrors"
 
 	"work-time-logging/configuration"
-	"work-time-logging/spreadsheet"
 )
 
 type WorkTime struct {
-	sheet  *spreadsheet.Spreadsheet
-	config *configuration.Config
+	storage Storage
+	config  *configuration.Config
+	strict  bool
 }
 
-func New(sheet *spreadsheet.Spreadsheet, config *configuration.Config) *WorkTime {
-	return &WorkTime{sheet: sheet, config: config}
-}
-
-func (this *WorkTime) getSheetName(year, month int) string {
-	return fmt.Sprintf("%04d%02d", year, month)
-}
-
-func (this *WorkTime) getPeriodCellAddress(recordIndex, periodIndex int, startOrEnd string) (string, error) {
-	row := 4 + recordIndex
-
-	col := int('C') + 2*periodIndex
-	if startOrEnd == "end" {
-		col++
-	}
-	return fmt.Sprintf("%c%d", rune(col), row), nil
-}
-
-func (this *WorkTime) getTravelExpenseCellAddress(recordIndex int) ([]string, error) {
-	row := 4 + recordIndex
-	return []string{fmt.Sprintf("J%d", row), fmt.Sprintf("K%d", row)}, nil
+func New(storage Storage, config *configuration.Config) *WorkTime {
+	return &WorkTime{storage: storage, config: config}
 }
 
 func (this *WorkTime) Get(projectName string, year, month int) (*MonthlyWorkTime, error) {
-	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
+	location, err := GetLocation(this.config, projectName)
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+		return nil, err
 	}
-	rows, err := this.sheet.Get(spreadsheetId, this.getSheetName(year, month), "A4", "K40")
+	monthlyWorkTime, err := this.storage.Get(projectName, year, month, location)
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to get sheet data: %w", err)
+		return nil, xerrors.Errorf("Unable to get work time data: %w", err)
+	}
+	if this.strict && len(monthlyWorkTime.Diagnostics) > 0 {
+		return nil, &ParseError{ProjectName: projectName, Year: year, Month: month, Diagnostics: monthlyWorkTime.Diagnostics}
 	}
-	monthlyWorkTime, err := parseMonthlyWorkTime(year, month, rows)
+
+	policy, err := GetBreakPolicy(this.config, projectName)
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to parse work time data: %w", err)
+		return nil, err
 	}
+	policy.Apply(monthlyWorkTime)
 	return monthlyWorkTime, nil
 }
 
-func (this *WorkTime) SetStart(projectName string, date *Date, time *Time) error {
+// SetStrict makes Get fail with ParseError if any row of a month is invalid,
+// instead of returning valid records with diagnostics.
+func (this *WorkTime) SetStrict(strict bool) {
+	this.strict = strict
+}
+
+func (this *WorkTime) getRecord(projectName string, date *Date) (*WorkTimeRecord, error) {
 	monthlyWorkTime, err := this.Get(projectName, date.Year, date.Month)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	recordIndex := -1
-	var record *WorkTimeRecord
-	for i, rcd := range monthlyWorkTime.Records {
-		if date.Equal(rcd.Date) {
-			recordIndex = i
-			record = &rcd
+	for i, record := range monthlyWorkTime.Records {
+		if date.Equal(record.Date) {
+			return &monthlyWorkTime.Records[i], nil
+		}
+	}
+	return nil, xerrors.Errorf("%s on %v: %w", projectName, date, ErrNotFound)
+}
+
+// findOpenPeriod returns the date and the index of the period which has been
+// started but not ended on the date or, as an overnight shift, on the previous
+// day, which may be in the previous month. The index is -1 if not found.
+// The previous month is regarded as having no periods if it does not exist.
+func (this *WorkTime) findOpenPeriod(projectName string, date *Date) (*Date, int, *Period, error) {
+	for _, d := range []*Date{date, date.AddDays(-1)} {
+		record, err := this.getRecord(projectName, d)
+		if err != nil && d.Month != date.Month && xerrors.Is(err, ErrMonthNotFound) {
 			break
 		}
+		if err != nil {
+			return nil, -1, nil, err
+		}
+		for i, period := range record.Periods {
+			if !period.IsEmpty() && period.IsEndEmpty() {
+				return d, i, &record.Periods[i], nil
+			}
+		}
+	}
+	return nil, -1, nil, nil
+}
+
+// isOvernight reports whether the period ends at time on the next day.
+func isOvernight(period *Period, time *Time) bool {
+	return time.Hour*60+time.Minute <= period.Start.Hour()*60+period.Start.Minute()
+}
+
+// GetOpenPeriod returns the period which has been started but not ended on
+// the day or on the previous day, or nil if there is no such period.
+func (this *WorkTime) GetOpenPeriod(projectName string, date *Date) (*Period, error) {
+	_, _, period, err := this.findOpenPeriod(projectName, date)
+	return period, err
+}
+
+// checkTime returns an error unless t is nil or a time of a day, as a start
+// or an end at 24:00 or later would be of the next day.
+func checkTime(startOrEnd string, t *Time) error {
+	if t != nil && (t.Hour < 0 || t.Hour > 23 || t.Minute < 0 || t.Minute > 59) {
+		return fmt.Errorf("Invalid %s: %s", startOrEnd, formatTime(t))
 	}
-	if recordIndex == -1 {
-		return fmt.Errorf("specified date not found")
+	return nil
+}
+
+func (this *WorkTime) SetStart(projectName string, date *Date, time *Time) error {
+	if err := checkTime("start", time); err != nil {
+		return err
+	}
+	record, err := this.getRecord(projectName, date)
+	if err != nil {
+		return err
 	}
 
-	periodIndex := -1
+	// Storages add a period to the day if all periods are used
+	periodIndex := len(record.Periods)
 	for i, period := range record.Periods {
 		if period.IsEndEmpty() {
 			if !period.IsEmpty() {
-				return fmt.Errorf("already started")
+				return xerrors.Errorf("%s on %v: %w", projectName, date, ErrAlreadyStarted)
 			}
 			periodIndex = i
 			break
 		}
 	}
-	if periodIndex == -1 {
-		return fmt.Errorf("empty period not found")
+
+	if err := this.guard(projectName, date, time, nil); err != nil {
+		return err
+	}
+	if err := this.storage.UpdatePeriod(projectName, date, periodIndex, "start", time); err != nil {
+		return xerrors.Errorf("Unable to set start: %w", err)
 	}
+	return nil
+}
 
-	addr, err := this.getPeriodCellAddress(recordIndex, periodIndex, "start")
+// SetEnd ends the open period of the day, or the one of the previous day
+// if time is past midnight of it.
+func (this *WorkTime) SetEnd(projectName string, date *Date, time *Time) error {
+	openDate, periodIndex, period, err := this.findOpenPeriod(projectName, date)
 	if err != nil {
 		return err
 	}
+	if periodIndex == -1 || (!openDate.Equal(date) && !isOvernight(period, time)) {
+		return xerrors.Errorf("%s on %v: %w", projectName, date, ErrNotStarted)
+	}
+
+	if err := this.guard(projectName, openDate, toTime(period.Start), time); err != nil {
+		return err
+	}
+	if err := this.storage.UpdatePeriod(projectName, openDate, periodIndex, "end", time); err != nil {
+		return xerrors.Errorf("Unable to set end: %w", err)
+	}
+	return nil
+}
 
-	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
+// guard checks the period to be written by SetStart or SetEnd with guardPeriod.
+func (this *WorkTime) guard(projectName string, date *Date, start, end *Time) error {
+	location, err := GetLocation(this.config, projectName)
 	if err != nil {
-		return xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+		return err
+	}
+	period, err := parsePeriod(date, formatTime(start), formatTime(end), location)
+	if err != nil {
+		return xerrors.Errorf("Invalid period: %w", err)
 	}
+	return this.guardPeriod(projectName, date, period)
+}
 
-	this.sheet.Update(spreadsheetId, this.getSheetName(date.Year, date.Month), addr,
-		fmt.Sprintf("%2d:%02d", time.Hour, time.Minute))
+func (this *WorkTime) SetTravelExpense(projectName string, date *Date, expense int, note string) error {
+	if _, err := this.getRecord(projectName, date); err != nil {
+		return err
+	}
 
+	if err := this.storage.UpdateTravelExpense(projectName, date, expense, note); err != nil {
+		return xerrors.Errorf("Unable to set travel expense: %w", err)
+	}
 	return nil
 }
 
-func (this *WorkTime) SetEnd(projectName string, date *Date, time *Time) error {
-	monthlyWorkTime, err := this.Get(projectName, date.Year, date.Month)
+// Switch ends the open period of any other project and starts a period of
+// projectName at the same time. endAt gives the date and time of the end in
+// each project, which is validated as SetEnd does. The end is cleared again
+// if the start fails. It returns the name of the project whose period was
+// ended, or an empty string if no period was open.
+func (this *WorkTime) Switch(projectName string, date *Date, time *Time, endAt func(projectName string) (*Date, *Time)) (string, error) {
+	if _, err := this.config.FindSpreadsheet(projectName); err != nil {
+		return "", err
+	}
+	openDate, periodIndex, period, err := this.findOpenPeriod(projectName, date)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if periodIndex != -1 && (openDate.Equal(date) || isOvernight(period, time)) {
+		return "", xerrors.Errorf("%s on %v: %w", projectName, date, ErrAlreadyStarted)
 	}
 
-	recordIndex := -1
-	var record *WorkTimeRecord
-	for i, rcd := range monthlyWorkTime.Records {
-		if date.Equal(rcd.Date) {
-			recordIndex = i
-			record = &rcd
-			break
+	ended := ""
+	var endedDate *Date
+	endedIndex := -1
+	for _, sheet := range this.config.Spreadsheets {
+		if sheet.Name == projectName {
+			continue
 		}
-	}
-	if recordIndex == -1 {
-		return fmt.Errorf("specified date not found")
+		endDate, endTime := endAt(sheet.Name)
+		openDate, periodIndex, period, err := this.findOpenPeriod(sheet.Name, endDate)
+		if err != nil {
+			return "", err
+		}
+		if periodIndex == -1 || (!openDate.Equal(endDate) && !isOvernight(period, endTime)) {
+			continue
+		}
+		if err := this.SetEnd(sheet.Name, endDate, endTime); err != nil {
+			return "", err
+		}
+		ended, endedDate, endedIndex = sheet.Name, openDate, periodIndex
+		break
 	}
 
-	periodIndex := -1
-	for i, period := range record.Periods {
-		if period.IsEndEmpty() {
-			if period.IsEmpty() {
-				return fmt.Errorf("not started")
+	if err := this.SetStart(projectName, date, time); err != nil {
+		if ended != "" {
+			if rbErr := this.storage.UpdatePeriod(ended, endedDate, endedIndex, "end", nil); rbErr != nil {
+				return "", xerrors.Errorf("%v (and unable to restore the end of %s: %v)", err, ended, rbErr)
 			}
-			periodIndex = i
-			break
 		}
+		return "", err
 	}
-	if periodIndex == -1 {
-		return fmt.Errorf("empty period not found")
+
+	return ended, nil
+}
+
+func toTime(t time.Time) *Time {
+	if t.Equal(time.Time{}) {
+		return nil
 	}
+	return &Time{Hour: t.Hour(), Minute: t.Minute()}
+}
 
-	addr, err := this.getPeriodCellAddress(recordIndex, periodIndex, "end")
-	if err != nil {
-		return err
+func formatTime(t *Time) string {
+	if t == nil {
+		return ""
 	}
+	return fmt.Sprintf("%d:%02d", t.Hour, t.Minute)
+}
 
-	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
+// EditPeriod overwrites the period at periodIndex of the day, which may be
+// next to the last period. The current start or end is kept if nil is given.
+// The result must be a well-formed period no longer than the maximum which
+// overlaps no other period of the day, and at most one period of the day can
+// be open.
+func (this *WorkTime) EditPeriod(projectName string, date *Date, periodIndex int, start, end *Time) error {
+	if err := checkTime("start", start); err != nil {
+		return err
+	}
+	if err := checkTime("end", end); err != nil {
+		return err
+	}
+	record, err := this.getRecord(projectName, date)
 	if err != nil {
-		return xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+		return err
+	}
+	if periodIndex < 0 || periodIndex > len(record.Periods) {
+		return fmt.Errorf("Invalid slot: %d", periodIndex+1)
+	}
+	if periodIndex < len(record.Periods) {
+		current := &record.Periods[periodIndex]
+		if start == nil {
+			start = toTime(current.Start)
+		}
+		if end == nil {
+			end = toTime(current.End)
+		}
 	}
 
-	this.sheet.Update(spreadsheetId, this.getSheetName(date.Year, date.Month), addr,
-		fmt.Sprintf("%2d:%02d", time.Hour, time.Minute))
-
-	return nil
-}
-
-func (this *WorkTime) SetTravelExpense(projectName string, date *Date, expense int, note string) error {
-	monthlyWorkTime, err := this.Get(projectName, date.Year, date.Month)
+	location, err := GetLocation(this.config, projectName)
 	if err != nil {
 		return err
 	}
+	period, err := parsePeriod(date, formatTime(start), formatTime(end), location)
+	if err != nil {
+		return xerrors.Errorf("Invalid period: %w", err)
+	}
+	if max := this.getMaxPeriodDuration(); period.GetDuration() > max {
+		return xerrors.Errorf("%s on %v: %s is longer than %v: %w",
+			projectName, date, formatPeriod(period), max, ErrTooLong)
+	}
 
-	recordIndex := -1
-	for i, rcd := range monthlyWorkTime.Records {
-		if date.Equal(rcd.Date) {
-			recordIndex = i
-			break
+	for i, p := range record.Periods {
+		if i == periodIndex || p.IsEmpty() {
+			continue
+		}
+		if p.IsEndEmpty() && period.IsEndEmpty() {
+			return xerrors.Errorf("%s on %v: slot %d: %w", projectName, date, i+1, ErrAlreadyStarted)
+		}
+		if overlaps(&p, period) {
+			return xerrors.Errorf("%s on %v: slot %d: %w", projectName, date, i+1, ErrOverlap)
 		}
 	}
-	if recordIndex == -1 {
-		return fmt.Errorf("specified date not found")
+
+	change := PeriodChange{Date: date, PeriodIndex: periodIndex, Start: start, End: end}
+	if err := this.storage.ReplacePeriods(projectName, []PeriodChange{change}); err != nil {
+		return xerrors.Errorf("Unable to edit period: %w", err)
 	}
+	return nil
+}
 
-	addrList, err := this.getTravelExpenseCellAddress(recordIndex)
+// ClearPeriod empties the period at periodIndex of the day.
+func (this *WorkTime) ClearPeriod(projectName string, date *Date, periodIndex int) error {
+	record, err := this.getRecord(projectName, date)
 	if err != nil {
 		return err
 	}
+	if periodIndex < 0 || periodIndex >= len(record.Periods) {
+		return fmt.Errorf("Invalid slot: %d", periodIndex+1)
+	}
 
-	spreadsheetId, err := this.config.FindSpreadsheetId(projectName)
-	if err != nil {
-		return xerrors.Errorf("Unable to find spreadsheet id: %w", err)
+	change := PeriodChange{Date: date, PeriodIndex: periodIndex}
+	if err := this.storage.ReplacePeriods(projectName, []PeriodChange{change}); err != nil {
+		return xerrors.Errorf("Unable to clear period: %w", err)
 	}
+	return nil
+}
 
-	this.sheet.Update(spreadsheetId, this.getSheetName(date.Year, date.Month),
-		addrList[0],
-		note)
-	this.sheet.Update(spreadsheetId, this.getSheetName(date.Year, date.Month),
-		addrList[1],
-		expense)
+// overlaps reports whether two non-empty periods share any time, where an
+// open period is regarded as the moment of its start.
+func overlaps(a, b *Period) bool {
+	extent := func(p *Period) (time.Time, time.Time) {
+		if p.IsEndEmpty() {
+			return p.Start, p.Start.Add(time.Nanosecond)
+		}
+		return p.Start, p.End
+	}
+	aStart, aEnd := extent(a)
+	bStart, bEnd := extent(b)
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
 
-	return nil
+// assumeEnd returns the period ended after d if it is open.
+func assumeEnd(p *Period, d time.Duration) *Period {
+	if p.IsEmpty() || !p.IsEndEmpty() {
+		return p
+	}
+	return &Period{Start: p.Start, End: p.Start.Add(d)}
 }
@@ -0,0 +1,282 @@
+package worktime
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"work-time-logging/configuration"
+)
+
+func newTestWorkTime(projectNames ...string) *WorkTime {
+	config := &configuration.Config{ConfigFile: configuration.ConfigFile{Storage: "memory", TimeZone: "Asia/Tokyo"}}
+	for _, name := range projectNames {
+		config.Spreadsheets = append(config.Spreadsheets, &configuration.SpreadsheetConfig{Name: name})
+	}
+	return New(NewMemoryStorage(), config)
+}
+
+func hm(hour, minute int) *Time {
+	return &Time{Hour: hour, Minute: minute}
+}
+
+// newTestRecord returns a record of periods such as {"9:00", "18:00"} in UTC.
+func newTestRecord(t *testing.T, date *Date, periods ...[2]string) WorkTimeRecord {
+	record := WorkTimeRecord{Date: date}
+	for _, p := range periods {
+		period, err := parsePeriod(date, p[0], p[1], time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Periods = append(record.Periods, *period)
+	}
+	return record
+}
+
+func TestMemoryStorageRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		periods [][2]*Time
+		want    time.Duration
+	}{
+		{"one period", [][2]*Time{{hm(9, 0), hm(12, 0)}}, 3 * time.Hour},
+		{"three periods", [][2]*Time{{hm(9, 0), hm(10, 0)}, {hm(11, 0), hm(12, 0)}, {hm(13, 0), hm(14, 30)}}, 3*time.Hour + 30*time.Minute},
+		{"fourth period", [][2]*Time{{hm(9, 0), hm(10, 0)}, {hm(11, 0), hm(12, 0)}, {hm(13, 0), hm(14, 0)}, {hm(15, 0), hm(16, 0)}}, 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			date := &Date{Year: 2026, Month: 10, Day: 5}
+			for _, p := range tt.periods {
+				if err := w.SetStart("a", date, p[0]); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.SetEnd("a", date, p[1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.SetTravelExpense("a", date, 500, "Tokyo - Shinagawa"); err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := w.getRecord("a", date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(record.Periods) < len(tt.periods) {
+				t.Fatalf("got %d periods, want %d", len(record.Periods), len(tt.periods))
+			}
+			for i, p := range tt.periods {
+				got := record.Periods[i]
+				if !isSameTime(got.Start, p[0]) || !isSameTime(got.End, p[1]) {
+					t.Errorf("period %d = %s, want %s-%s", i+1, formatPeriod(&got), formatTime(p[0]), formatTime(p[1]))
+				}
+			}
+			if got := record.GetDuration(); got != tt.want {
+				t.Errorf("duration = %v, want %v", got, tt.want)
+			}
+			if te := record.TravelExpense; te == nil || te.Expense != 500 || te.Note != "Tokyo - Shinagawa" {
+				t.Errorf("travel expense = %+v", te)
+			}
+		})
+	}
+}
+
+func TestSetEndOvernightAcrossMonth(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end *Date
+	}{
+		{"month", &Date{Year: 2026, Month: 10, Day: 31}, &Date{Year: 2026, Month: 11, Day: 1}},
+		{"year", &Date{Year: 2026, Month: 12, Day: 31}, &Date{Year: 2027, Month: 1, Day: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			if err := w.SetStart("a", tt.start, hm(22, 0)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.SetEnd("a", tt.end, hm(2, 0)); err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := w.getRecord("a", tt.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := record.Periods[0]
+			if got := p.GetDuration(); got != 4*time.Hour {
+				t.Errorf("duration = %v, want 4h", got)
+			}
+			if p.End.Day() != tt.end.Day || int(p.End.Month()) != tt.end.Month {
+				t.Errorf("end = %v, want on %v", p.End, tt.end)
+			}
+			next, err := w.getRecord("a", tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range next.Periods {
+				if !p.IsEmpty() {
+					t.Errorf("%v has a period %s", tt.end, formatPeriod(&p))
+				}
+			}
+		})
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	day := func(d int) *Date {
+		return &Date{Year: 2026, Month: 10, Day: d}
+	}
+	tests := []struct {
+		name      string
+		started   *Date
+		start     *Time
+		end       *Date
+		endTime   *Time
+		wantEnded string
+		wantErr   error
+	}{
+		// 10:00 in Tokyo is 21:00 of the previous day in New York
+		{"time zone", day(4), hm(20, 0), day(4), hm(21, 0), "a", nil},
+		{"too long", day(4), hm(9, 0), day(5), hm(5, 0), "", ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a", "b")
+			w.config.Spreadsheets[0].TimeZone = "America/New_York"
+			if err := w.SetStart("a", tt.started, tt.start); err != nil {
+				t.Fatal(err)
+			}
+			endAt := func(projectName string) (*Date, *Time) {
+				return tt.end, tt.endTime
+			}
+			ended, err := w.Switch("b", day(5), hm(10, 0), endAt)
+			if !xerrors.Is(err, tt.wantErr) || ended != tt.wantEnded {
+				t.Fatalf("Switch = %q, %v, want %q, %v", ended, err, tt.wantEnded, tt.wantErr)
+			}
+
+			record, err := w.getRecord("a", tt.started)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr == nil && !isSameTime(record.Periods[0].End, tt.endTime) {
+				t.Errorf("end of a = %s, want %s", formatPeriod(&record.Periods[0]), formatTime(tt.endTime))
+			}
+			if tt.wantErr != nil && !record.Periods[0].IsEndEmpty() {
+				t.Errorf("a is ended at %s", formatPeriod(&record.Periods[0]))
+			}
+			if open, err := w.GetOpenPeriod("b", day(5)); err != nil || (open != nil) != (tt.wantErr == nil) {
+				t.Errorf("open period of b = %v, %v", open, err)
+			}
+		})
+	}
+}
+
+func TestEditPeriod(t *testing.T) {
+	date := &Date{Year: 2026, Month: 10, Day: 5}
+	tests := []struct {
+		name       string
+		slot       int
+		start, end *Time
+		want       string
+		wantErr    error
+	}{
+		{"edit", 0, hm(8, 30), hm(12, 15), "8:30-12:15", nil},
+		{"keep start", 0, nil, hm(11, 0), "9:00-11:00", nil},
+		{"next slot", 2, hm(19, 0), hm(20, 0), "19:00-20:00", nil},
+		{"overlap", 1, hm(11, 0), hm(14, 0), "", ErrOverlap},
+		{"second open", 2, hm(19, 0), nil, "", ErrAlreadyStarted},
+		{"too long", 0, hm(9, 0), hm(8, 0), "", ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			if err := w.EditPeriod("a", date, 0, hm(9, 0), hm(12, 0)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.EditPeriod("a", date, 1, hm(13, 0), nil); err != nil {
+				t.Fatal(err)
+			}
+			err := w.EditPeriod("a", date, tt.slot, tt.start, tt.end)
+			if !xerrors.Is(err, tt.wantErr) {
+				t.Fatalf("EditPeriod = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			record, err := w.getRecord("a", date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := formatPeriod(&record.Periods[tt.slot]); got != tt.want {
+				t.Errorf("slot %d = %s, want %s", tt.slot+1, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditPeriodInvalid(t *testing.T) {
+	date := &Date{Year: 2026, Month: 10, Day: 5}
+	tests := []struct {
+		name       string
+		slot       int
+		start, end *Time
+	}{
+		{"end out of range", 0, hm(9, 0), hm(40, 75)},
+		{"start out of range", 0, hm(99, 0), hm(18, 0)},
+		{"negative minute", 0, hm(9, -1), nil},
+		{"negative slot", -1, hm(9, 0), hm(10, 0)},
+		{"slot after next", 4, hm(9, 0), hm(10, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			if err := w.EditPeriod("a", date, tt.slot, tt.start, tt.end); err == nil {
+				t.Fatal("EditPeriod succeeded")
+			}
+			record, err := w.getRecord("a", date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range record.Periods {
+				if !p.IsEmpty() {
+					t.Errorf("period %s is written", formatPeriod(&p))
+				}
+			}
+		})
+	}
+}
+
+func TestClearPeriod(t *testing.T) {
+	date := &Date{Year: 2026, Month: 10, Day: 5}
+	tests := []struct {
+		name    string
+		slot    int
+		wantErr bool
+	}{
+		{"clear", 0, false},
+		{"empty slot", 2, false},
+		{"out of range", 3, true},
+		{"negative", -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkTime("a")
+			if err := w.EditPeriod("a", date, 0, hm(9, 0), hm(12, 0)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.ClearPeriod("a", date, tt.slot); (err != nil) != tt.wantErr {
+				t.Fatalf("ClearPeriod = %v, want error %v", err, tt.wantErr)
+			}
+			record, err := w.getRecord("a", date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cleared := record.Periods[0].IsEmpty(); cleared != (tt.slot == 0) {
+				t.Errorf("slot 1 = %s", formatPeriod(&record.Periods[0]))
+			}
+		})
+	}
+}